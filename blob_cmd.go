@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/images"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store/bunstore"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+var (
+	// blobStore is the store of the blob commands, which references the blobs.
+	blobStore store.Store
+	// blobs is the blob storage of the blob commands.
+	blobs blob.Store
+)
+
+func newBlobCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "blob",
+		Usage: "blob storage maintenance",
+		Before: func(c *cli.Context) error {
+			if err := connectDB(c); err != nil {
+				return err
+			}
+
+			var err error
+			if blobs, err = blob.New(c.Context, config.C.Load()); err != nil {
+				return err
+			}
+
+			blobStore = bunstore.New(models.GetDB())
+
+			return nil
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "gc",
+				Usage: "delete all blobs, which are not referenced anymore",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "only print the result without deleting any blob",
+					},
+					&cli.DurationFlag{
+						Name:  "min-age",
+						Usage: "minimum age of deleted blobs (default: blob.gc_min_age)",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "print the result as JSON",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					minAge := config.C.Load().Blob.GCMinAge
+					if c.IsSet("min-age") {
+						minAge = c.Duration("min-age")
+					}
+
+					res, err := images.GC(c.Context, blobStore, blobs, minAge, c.Bool("dry-run"))
+					if err != nil {
+						return err
+					}
+
+					if c.Bool("json") {
+						enc := json.NewEncoder(os.Stdout)
+						enc.SetIndent("", "  ")
+
+						return enc.Encode(res)
+					}
+
+					verb := "deleted"
+					if c.Bool("dry-run") {
+						verb = "would delete"
+					}
+
+					fmt.Printf("scanned %d blobs, %s %d blobs (%d bytes)\n", res.Scanned, verb, res.Deleted, res.Freed)
+
+					return nil
+				},
+			},
+			{
+				Name:  "import-images",
+				Usage: "import all remote bookmark images into the blob storage",
+				Action: func(c *cli.Context) error {
+					res, err := images.ImportBookmarks(c.Context, blobStore, blobs)
+					if err != nil {
+						return err
+					}
+
+					fmt.Printf("imported %d images, %d failed\n", res.Imported, res.Failed)
+
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/multierr"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+func newConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "configuration management",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "check",
+				Usage: "validate the configuration and report all problems",
+				Action: func(c *cli.Context) error {
+					cfg, err := config.Parse(c.String("config"), flagOverrides(c))
+					if err != nil {
+						return err
+					}
+
+					errs := multierr.Errors(cfg.Validate())
+					if len(errs) == 0 {
+						fmt.Printf("configuration is valid\n")
+						return nil
+					}
+
+					for _, err := range errs {
+						fmt.Fprintf(os.Stderr, "- %s\n", err)
+					}
+
+					return cli.Exit(fmt.Sprintf("configuration has %d problem(s)", len(errs)), 1)
+				},
+			},
+			{
+				Name:  "print",
+				Usage: "print the effective configuration",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "redacted",
+						Usage: "replace all secrets",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					cfg, err := config.Parse(c.String("config"), flagOverrides(c))
+					if err != nil {
+						return err
+					}
+
+					data, err := cfg.Marshal(c.Bool("redacted"))
+					if err != nil {
+						return err
+					}
+
+					fmt.Print(string(data))
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/seed"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store/bunstore"
+	"github.com/fabmation-gmbh/briefkasten-go/migrations"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+func newDBSeedCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "seed",
+		Usage: "populate the database with generated users, categories, tags and bookmarks",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "users",
+				Usage: "number of users",
+				Value: 10,
+			},
+			&cli.IntFlag{
+				Name:  "categories",
+				Usage: "number of categories per user",
+				Value: 5,
+			},
+			&cli.IntFlag{
+				Name:  "tags",
+				Usage: "number of tags per user",
+				Value: 10,
+			},
+			&cli.IntFlag{
+				Name:  "bookmarks",
+				Usage: "number of bookmarks per user",
+				Value: 50,
+			},
+			&cli.IntFlag{
+				Name:  "max-tags-per-bookmark",
+				Usage: "maximum number of tags of a bookmark",
+				Value: 3,
+			},
+			&cli.Int64Flag{
+				Name:  "seed",
+				Usage: "seed of the random generator, the same seed generates the same data",
+				Value: 1,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			for _, name := range []string{"users", "categories", "tags", "bookmarks", "max-tags-per-bookmark"} {
+				if c.Int(name) < 0 {
+					return cli.Exit(fmt.Sprintf("--%s must not be negative", name), 1)
+				}
+			}
+
+			res, err := seed.Run(c.Context, bunstore.New(models.GetDB()), seed.Options{
+				Users:              c.Int("users"),
+				Categories:         c.Int("categories"),
+				Tags:               c.Int("tags"),
+				Bookmarks:          c.Int("bookmarks"),
+				MaxTagsPerBookmark: c.Int("max-tags-per-bookmark"),
+				Seed:               c.Int64("seed"),
+				Now:                time.Now(),
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("created %d users, %d categories, %d tags and %d bookmarks\n",
+				res.Users, res.Categories, res.Tags, res.Bookmarks)
+
+			return nil
+		},
+	}
+}
+
+func newDBResetCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "reset",
+		Usage: "drop all tables, re-create and migrate the database (development only)",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "do not ask for confirmation",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if env := config.C.Load().General.Environment; strings.EqualFold(env, "production") {
+				return cli.Exit("refusing to reset the database in the production environment", 1)
+			}
+
+			if !c.Bool("yes") && !confirm("Drop all tables and delete all data of the database?") {
+				return cli.Exit("aborted", 1)
+			}
+
+			if err := models.DropTables(c.Context); err != nil {
+				return err
+			}
+
+			if err := migrator.Init(c.Context); err != nil {
+				return err
+			}
+
+			group, err := migrator.Migrate(c.Context)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("database reset and migrated to %s\n", group)
+
+			return nil
+		},
+	}
+}
+
+// printPendingSQL prints the SQL of all unapplied migrations.
+func printPendingSQL(c *cli.Context) error {
+	ms, err := migrator.MigrationsWithStatus(c.Context)
+	if err != nil {
+		return err
+	}
+
+	unapplied := ms.Unapplied()
+	if len(unapplied) == 0 {
+		fmt.Printf("-- there are no new migrations to run (database is up to date)\n")
+		return nil
+	}
+
+	for _, m := range unapplied {
+		if migrations.IsGo(m) {
+			fmt.Printf("-- migration %s\n-- Go migration, the SQL is not known in advance\n", m)
+			continue
+		}
+
+		sql, err := migrations.UpSQL(models.Dialect(), m)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("-- migration %s\n%s\n", m, strings.TrimSpace(sql))
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+func TestDBCommandValidation(t *testing.T) {
+	defer config.C.Store(config.C.Load())
+
+	var cfg config.Config
+	cfg.General.Environment = "Production"
+	config.C.Store(&cfg)
+
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{args: []string{"seed", "--users", "-1"}, err: "--users must not be negative"},
+		{args: []string{"seed", "--bookmarks", "-5"}, err: "--bookmarks must not be negative"},
+		{args: []string{"seed", "--max-tags-per-bookmark", "-1"}, err: "--max-tags-per-bookmark must not be negative"},
+		// the environment is compared case-insensitively, like in the validation
+		{args: []string{"reset", "--yes"}, err: "refusing to reset the database in the production environment"},
+	}
+
+	for _, tt := range tests {
+		app := &cli.App{
+			Commands:       []*cli.Command{newDBSeedCommand(), newDBResetCommand()},
+			ExitErrHandler: func(*cli.Context, error) {},
+		}
+
+		err := app.Run(append([]string{"briefkasten"}, tt.args...))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%v: expected the error %q, got %v", tt.args, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/pprof"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/audit"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// debugSettings are the debug settings which can be changed at runtime.
+type debugSettings struct {
+	LogLevel string `json:"log_level"`
+	SQLDebug bool   `json:"sql_debug"`
+}
+
+// addAdminRoutes adds all admin endpoints to the internal listener.
+func addAdminRoutes(r fiber.Router) {
+	cfg := config.C.Load()
+
+	if cfg.Internal.AdminToken == "" {
+		log.Debug("Admin endpoints are disabled, no admin token configured")
+		return
+	}
+
+	if cfg.Internal.EnablePprof {
+		r.Use("/debug/pprof", requireAdminToken, pprof.New())
+	}
+
+	admin := r.Group("/admin", requireAdminToken)
+	admin.Get("/debug", GetDebugSettings)
+	admin.Patch("/debug", UpdateDebugSettings)
+}
+
+// requireAdminToken rejects all requests without the configured admin token.
+func requireAdminToken(c *fiber.Ctx) error {
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(config.C.Load().Internal.AdminToken)) != 1 {
+		return rerr.Unauthenticated.WithLogMsg("invalid admin token")
+	}
+
+	return c.Next()
+}
+
+// GetDebugSettings returns the current debug settings.
+func GetDebugSettings(c *fiber.Ctx) error {
+	return c.JSON(debugSettings{
+		LogLevel: log.Level(),
+		SQLDebug: models.SQLDebug(),
+	})
+}
+
+// UpdateDebugSettings changes the debug settings.
+// Only the provided settings are changed.
+func UpdateDebugSettings(c *fiber.Ctx) error {
+	var req struct {
+		LogLevel *string `json:"log_level"`
+		SQLDebug *bool   `json:"sql_debug"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("unable to parse body")
+	}
+
+	ctx := ftracer.FromCtx(c)
+	actor := "admin-api:" + c.IP()
+
+	if req.LogLevel != nil {
+		if err := SetLogLevel(ctx, actor, *req.LogLevel); err != nil {
+			return rerr.RequestMalformed.With(err).WithLogMsg("invalid log level")
+		}
+	}
+
+	if req.SQLDebug != nil {
+		SetSQLDebug(ctx, actor, *req.SQLDebug)
+	}
+
+	return GetDebugSettings(c)
+}
+
+// SetLogLevel changes the log level and records the change in the audit trail.
+func SetLogLevel(ctx context.Context, actor, level string) error {
+	old, err := log.SetLevel(level)
+	if err != nil {
+		return err
+	}
+
+	if old != log.Level() {
+		audit.Record(ctx, audit.Entry{
+			Actor:    actor,
+			Action:   "log.level",
+			OldValue: old,
+			NewValue: log.Level(),
+		})
+	}
+
+	return nil
+}
+
+// SetSQLDebug toggles the SQL debug output and records the change in the audit trail.
+func SetSQLDebug(ctx context.Context, actor string, enabled bool) {
+	if old := models.SetSQLDebug(enabled); old != enabled {
+		audit.Record(ctx, audit.Entry{
+			Actor:    actor,
+			Action:   "debug.enable_sql_debug",
+			OldValue: old,
+			NewValue: enabled,
+		})
+	}
+}
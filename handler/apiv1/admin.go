@@ -0,0 +1,165 @@
+package apiv1
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/middleware"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/audit"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+)
+
+// AdminUserStats is the response of the user stats endpoint.
+type AdminUserStats struct {
+	store.UserCounts
+
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// AdminListUsers returns all users, optionally filtered by the "query" parameter.
+func (s *Server) AdminListUsers(c *fiber.Ctx) error {
+	page, err := parsePage(c)
+	if err != nil {
+		return err
+	}
+
+	users, err := s.store.Users.List(ftracer.FromCtx(c), c.Query("query"), page)
+	if err != nil {
+		return storeErr(err, "unable to retrieve users")
+	}
+
+	for i, u := range users {
+		users[i] = userResponse(u)
+	}
+
+	return c.JSON(users)
+}
+
+// AdminGetUser returns a user.
+func (s *Server) AdminGetUser(c *fiber.Ctx) error {
+	id, err := adminUserID(c)
+	if err != nil {
+		return err
+	}
+
+	u, err := s.store.Users.Get(ftracer.FromCtx(c), id)
+	if err != nil {
+		return storeErr(err, "unable to retrieve user")
+	}
+
+	return c.JSON(userResponse(u))
+}
+
+// AdminGetUserStats returns the number of records stored by a user.
+func (s *Server) AdminGetUserStats(c *fiber.Ctx) error {
+	id, err := adminUserID(c)
+	if err != nil {
+		return err
+	}
+
+	counts, err := s.store.Users.Counts(ftracer.FromCtx(c), id)
+	if err != nil {
+		return storeErr(err, "unable to count user records")
+	}
+
+	return c.JSON(AdminUserStats{UserCounts: counts, UserID: id})
+}
+
+// AdminDisableUser disables a user and revokes all tokens of the user.
+func (s *Server) AdminDisableUser(c *fiber.Ctx) error {
+	return s.adminSetDisabled(c, true)
+}
+
+// AdminEnableUser enables a disabled user.
+func (s *Server) AdminEnableUser(c *fiber.Ctx) error {
+	return s.adminSetDisabled(c, false)
+}
+
+// AdminLogoutUser revokes all tokens of a user.
+func (s *Server) AdminLogoutUser(c *fiber.Ctx) error {
+	id, err := adminUserID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := ftracer.FromCtx(c)
+
+	if err := s.store.Users.RevokeTokens(ctx, id); err != nil {
+		return storeErr(err, "unable to revoke user tokens")
+	}
+
+	purgeSessions(ctx, id)
+
+	recordAdminAction(c, "user.logout", nil, id)
+
+	return c.JSON(fiber.Map{"message": "Logged out"})
+}
+
+func (s *Server) adminSetDisabled(c *fiber.Ctx, disabled bool) error {
+	id, err := adminUserID(c)
+	if err != nil {
+		return err
+	}
+
+	// an admin must not lock themselves out
+	if self, _ := authUserID(c); disabled && self == id {
+		return rerr.RequestMalformed.WithLogMsg("admins can not disable themselves")
+	}
+
+	ctx := ftracer.FromCtx(c)
+
+	old, err := s.store.Users.Get(ctx, id)
+	if err != nil {
+		return storeErr(err, "unable to retrieve user")
+	}
+
+	if err := s.store.Users.SetDisabled(ctx, id, disabled); err != nil {
+		return storeErr(err, "unable to update user")
+	}
+
+	action := "user.enable"
+
+	if disabled {
+		action = "user.disable"
+
+		if err := s.store.Users.RevokeTokens(ctx, id); err != nil {
+			return storeErr(err, "unable to revoke user tokens")
+		}
+
+		purgeSessions(ctx, id)
+	}
+
+	recordAdminAction(c, action,
+		fiber.Map{"user_id": id, "disabled": old.IsDisabled()},
+		fiber.Map{"user_id": id, "disabled": disabled},
+	)
+
+	u, err := s.store.Users.Get(ctx, id)
+	if err != nil {
+		return storeErr(err, "unable to retrieve user")
+	}
+
+	return c.JSON(userResponse(u))
+}
+
+// adminUserID returns the ID of the ":user_id" path parameter.
+func adminUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := parseUUIDParam(c, "user_id")
+	if err != nil {
+		return uuid.Nil, rerr.RequestMalformed.With(err).WithLogMsg("invalid user ID")
+	}
+
+	return id, nil
+}
+
+// recordAdminAction writes the action of the authenticated admin to the audit trail.
+func recordAdminAction(c *fiber.Ctx, action string, oldValue, newValue any) {
+	audit.Record(ftracer.FromCtx(c), audit.Entry{
+		Actor:    "user:" + middleware.UserIDFromCtx(c),
+		Action:   action,
+		OldValue: oldValue,
+		NewValue: newValue,
+	})
+}
@@ -0,0 +1,172 @@
+package apiv1_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/apiv1"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/apitest"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+func TestAdmin(t *testing.T) {
+	h := apitest.New(t, map[string]any{"oauth.providers": []string{"fake"}})
+
+	alice := h.User("alice")
+	bob := h.User("bob")
+	carol := h.User("carol")
+
+	// issued before any revocation
+	aliceToken := h.Token(alice.ID)
+	bobToken := h.Token(bob.ID)
+	carolToken := h.Token(carol.ID)
+
+	login(t, h, alice)
+	login(t, h, bob)
+
+	tests := []struct {
+		name   string
+		req    apitest.Request
+		status int
+		check  func(t *testing.T, resp apitest.Response)
+	}{
+		{
+			name:   "list without admin role",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/admin/users", Token: aliceToken},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "list",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/admin/users", Token: carolToken},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var users []models.UserAccount
+				resp.Decode(t, &users)
+
+				if len(users) != 3 {
+					t.Errorf("expected 3 users, got %+v", users)
+				}
+			},
+		},
+		{
+			name:   "search",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/admin/users?query=BOB", Token: carolToken},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var users []models.UserAccount
+				resp.Decode(t, &users)
+
+				if len(users) != 1 || users[0].ID != bob.ID {
+					t.Errorf("expected only bob, got %+v", users)
+				}
+			},
+		},
+		{
+			name:   "get unknown user",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/admin/users/7f4ec1a6-1d8c-4c55-9d86-4b1a3a5f0aff", Token: carolToken},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "stats",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/admin/users/" + alice.ID.String() + "/stats", Token: carolToken},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var stats apiv1.AdminUserStats
+				resp.Decode(t, &stats)
+
+				if stats.UserID != alice.ID || stats.Categories != 1 || stats.Tags != 1 || stats.Bookmarks != 1 {
+					t.Errorf("unexpected stats %+v", stats)
+				}
+			},
+		},
+		{
+			name:   "disable self",
+			req:    apitest.Request{Method: http.MethodPost, Path: "/api/v1/admin/users/" + carol.ID.String() + "/disable", Token: carolToken},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "disable",
+			req:    apitest.Request{Method: http.MethodPost, Path: "/api/v1/admin/users/" + bob.ID.String() + "/disable", Token: carolToken},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var u models.UserAccount
+				resp.Decode(t, &u)
+
+				if !u.IsDisabled() {
+					t.Errorf("expected disabled user, got %+v", u)
+				}
+
+				if ids := sessionIDs(t, h, bob.ID); len(ids) != 0 {
+					t.Errorf("expected the sessions of bob to be purged, got %v", ids)
+				}
+
+				var entry models.AuditLog
+				if err := h.DB.NewSelect().Model(&entry).Where("action = ?", "user.disable").Scan(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+
+				oldValue := `{"disabled":false,"user_id":"` + bob.ID.String() + `"}`
+				newValue := `{"disabled":true,"user_id":"` + bob.ID.String() + `"}`
+				if entry.OldValue.String != oldValue || entry.NewValue.String != newValue {
+					t.Errorf("expected the audit values %s and %s, got %s and %s", oldValue, newValue, entry.OldValue.String, entry.NewValue.String)
+				}
+			},
+		},
+		{
+			name:   "token of disabled user",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/" + bob.ID.String() + "/tags", Token: bobToken},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "token before logout",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/" + alice.ID.String() + "/tags", Token: aliceToken},
+			status: http.StatusOK,
+		},
+		{
+			name:   "logout",
+			req:    apitest.Request{Method: http.MethodPost, Path: "/api/v1/admin/users/" + alice.ID.String() + "/logout", Token: carolToken},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				if ids := sessionIDs(t, h, alice.ID); len(ids) != 0 {
+					t.Errorf("expected the sessions of alice to be purged, got %v", ids)
+				}
+			},
+		},
+		{
+			name:   "token after logout",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/" + alice.ID.String() + "/tags", Token: aliceToken},
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := h.Do(tt.req)
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, resp.StatusCode, resp.Body)
+			}
+
+			if tt.check != nil {
+				tt.check(t, resp)
+			}
+		})
+	}
+
+	t.Run("role change revokes tokens", func(t *testing.T) {
+		if err := h.Store.Users.SetRole(context.Background(), carol.ID, models.RoleUser); err != nil {
+			t.Fatal(err)
+		}
+
+		resp := h.Do(apitest.Request{Method: http.MethodGet, Path: "/api/v1/admin/users", Token: carolToken})
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusBadRequest, resp.StatusCode, resp.Body)
+		}
+
+		resp = h.Do(apitest.Request{Method: http.MethodGet, Path: "/api/v1/admin/users", Token: h.Token(carol.ID)})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusForbidden, resp.StatusCode, resp.Body)
+		}
+	})
+}
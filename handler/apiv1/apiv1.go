@@ -1,31 +1,73 @@
 package apiv1
 
 import (
+	"github.com/fabmation-gmbh/briefkasten-go/handler/middleware"
 	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
 	"github.com/gofiber/fiber/v2"
 	jwtware "github.com/gofiber/jwt/v3"
 )
 
 // AddApiV1 will add all v1 API handlers to the Mux router.
-func AddApiV1(r fiber.Router) {
+func AddApiV1(r fiber.Router, s *Server) {
 	// ========================================================
 	// Unauthenticated routes
 
-	r.Get("/oauth2/login/:provider", AuthLogin)
-	r.Get("/oauth2/callback/:provider", OAuthCallback)
+	r.Get("/oauth2/login/:provider", s.AuthLogin)
+	r.Get("/oauth2/callback/:provider", s.OAuthCallback)
+
+	// blob URLs are signed, see blob.URL
+	r.Get("/blobs/:key", s.GetBlob)
 
 	// TODO: Implement logout
 
 	// ========================================================
 	// Authenticated routes
 
+	// NOTE: Changing the JWT configuration requires a restart.
+	jwtCfg := config.C.Load().General.JWT
+
 	r.Use(jwtware.New(jwtware.Config{
-		SigningMethod: config.C.General.JWT.SigningMethod,
-		SigningKey:    []byte(config.C.General.JWT.SigningKey),
+		SigningMethod: jwtCfg.SigningMethod,
+		SigningKey:    []byte(jwtCfg.SigningKey),
 	}))
 
-	r.Get("/users/:id/tags", GetTags)
-	r.Delete("/users/:id/tags/:tag_id", DeleteTag)
-	r.Put("/users/:id/tags/:tag_id", UpdateTag)
-	r.Post("/users/:id/tags", CreateTag)
+	// rejects tokens of deleted users and revoked tokens
+	r.Use(middleware.NewSession(s.store.Users))
+
+	// all modifying requests run in a transaction, which is committed
+	// if the handler succeeds and rolled back otherwise.
+	r.Use(middleware.NewTransaction(s.store))
+
+	r.Get("/users/me", s.GetProfile)
+	r.Patch("/users/me", s.UpdateProfile)
+	r.Put("/users/me/avatar", s.UploadAvatar)
+	r.Delete("/users/me/avatar", s.DeleteAvatar)
+
+	r.Delete("/users/:id", s.DeleteUser)
+	r.Get("/users/:id/export", s.ExportUser)
+
+	r.Get("/users/:id/tags", s.GetTags)
+	r.Delete("/users/:id/tags/:tag_id", s.DeleteTag)
+	r.Put("/users/:id/tags/:tag_id", s.UpdateTag)
+	r.Post("/users/:id/tags", s.CreateTag)
+
+	r.Get("/users/:id/categories", s.GetCategories)
+	r.Delete("/users/:id/categories/:category_id", s.DeleteCategory)
+	r.Put("/users/:id/categories/:category_id", s.UpdateCategory)
+	r.Post("/users/:id/categories", s.CreateCategory)
+
+	r.Get("/users/:id/bookmarks", s.GetBookmarks)
+	r.Get("/users/:id/bookmarks/:bookmark_id", s.GetBookmark)
+	r.Delete("/users/:id/bookmarks/:bookmark_id", s.DeleteBookmark)
+	r.Put("/users/:id/bookmarks/:bookmark_id", s.UpdateBookmark)
+	r.Post("/users/:id/bookmarks", s.CreateBookmark)
+
+	admin := r.Group("/admin", middleware.RequireRole(models.RoleAdmin))
+	admin.Get("/users", s.AdminListUsers)
+	admin.Get("/users/:user_id", s.AdminGetUser)
+	admin.Get("/users/:user_id/stats", s.AdminGetUserStats)
+	admin.Post("/users/:user_id/disable", s.AdminDisableUser)
+	admin.Post("/users/:user_id/enable", s.AdminEnableUser)
+	admin.Post("/users/:user_id/logout", s.AdminLogoutUser)
 }
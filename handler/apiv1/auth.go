@@ -4,24 +4,24 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
 	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/auth"
 	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/oauth"
 	"github.com/fabmation-gmbh/briefkasten-go/internal/redis"
 	"github.com/fabmation-gmbh/briefkasten-go/models"
 	"github.com/fabmation-gmbh/briefkasten-go/pkg/helper"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
-	"github.com/markbates/goth"
+	"github.com/google/uuid"
 )
 
 // AuthLogin is the authentication endpoint.
-func AuthLogin(c *fiber.Ctx) error {
+func (s *Server) AuthLogin(c *fiber.Ctx) error {
 	provider := strings.ToLower(c.Params("provider"))
 
-	prov, err := goth.GetProvider(provider)
+	prov, err := oauth.GetProvider(provider)
 	if err != nil {
 		return rerr.RequestMalformed.With(err).WithLogMsg("unable to resolve requested provider")
 	}
@@ -46,7 +46,8 @@ func AuthLogin(c *fiber.Ctx) error {
 		Provider: provider,
 	}
 
-	if err := redis.StoreUserSession(ctx, state, userSes); err != nil {
+	// the user is not known before the callback
+	if err := redis.StoreUserSession(ctx, uuid.Nil, state, userSes); err != nil {
 		return rerr.InternalServerError.With(err).WithLogMsg("unable to store session")
 	}
 
@@ -54,10 +55,10 @@ func AuthLogin(c *fiber.Ctx) error {
 }
 
 // OAuthCallback is the oauth2 callback handler.
-func OAuthCallback(c *fiber.Ctx) error {
+func (s *Server) OAuthCallback(c *fiber.Ctx) error {
 	provider := strings.ToLower(c.Params("provider"))
 
-	prov, err := goth.GetProvider(provider)
+	prov, err := oauth.GetProvider(provider)
 	if err != nil {
 		return rerr.RequestMalformed.With(err).WithLogMsg("unable to resolve requested provider")
 	}
@@ -82,6 +83,9 @@ func OAuthCallback(c *fiber.Ctx) error {
 		return rerr.RequestMalformed.WithLogMsg("state value missmatch")
 	}
 
+	// the state must not be used twice
+	redis.DeleteUserSession(ctx, cookieSession)
+
 	oUser, err := prov.FetchUser(ses.Session)
 	if err != nil {
 		// TODO:sess.Authorize(provider, params) and retry FetchUser
@@ -94,23 +98,26 @@ func OAuthCallback(c *fiber.Ctx) error {
 		Name:  oUser.Name,
 	}
 
-	u, err := models.GetOrCreateUser(ctx, acc)
+	u, err := s.store.Users.GetOrCreate(ctx, acc)
 	if err != nil {
-		return rerr.RequestMalformed.WithLogMsg("unable to retrieve or create user")
+		return storeErr(err, "unable to retrieve or create user")
 	}
 
-	// TODO: Store the access token longer?
-
-	claims := jwt.MapClaims{
-		"user_id": u.ID.String(),
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
+	if u.IsDisabled() {
+		return rerr.PermissionDenied.WithLogMsg("login of disabled user")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	u = s.importAvatar(c, u, oUser.AvatarURL)
+
+	// the provider session is kept for the user under a new ID, since the
+	// state is known to the client, and it is deleted with the user
+	if err := redis.StoreUserSession(ctx, u.ID, helper.RandString(64), ses); err != nil {
+		return rerr.InternalServerError.With(err).WithLogMsg("unable to store session")
+	}
 
-	t, err := token.SignedString(String2bytes(config.C.General.JWT.SigningKey))
+	t, err := auth.NewToken(u, auth.SessionTTL)
 	if err != nil {
-		return rerr.InternalServerError.WithLogMsg("unable to sign JWT token")
+		return rerr.InternalServerError.With(err).WithLogMsg("unable to sign JWT token")
 	}
 
 	tokenData, err := json.Marshal(t)
@@ -121,8 +128,8 @@ func OAuthCallback(c *fiber.Ctx) error {
 	c.Cookie(&fiber.Cookie{
 		Name:     "briefkasten_jwt",
 		Value:    byteSlice2String(tokenData),
-		MaxAge:   int((time.Hour * 72).Seconds()),
-		Secure:   config.C.General.SecureCookie,
+		MaxAge:   int(auth.SessionTTL.Seconds()),
+		Secure:   config.C.Load().General.SecureCookie,
 		HTTPOnly: true,
 	})
 
@@ -0,0 +1,301 @@
+package apiv1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/apitest"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/redis"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// fakeProvider is a goth provider, which authenticates every session as user.
+type fakeProvider struct {
+	user goth.User
+}
+
+func (p *fakeProvider) Name() string        { return "fake" }
+func (p *fakeProvider) SetName(name string) {}
+func (p *fakeProvider) Debug(bool)          {}
+
+func (p *fakeProvider) BeginAuth(state string) (goth.Session, error) {
+	return &fakeSession{AuthURL: "https://auth.example.com/authorize?state=" + state}, nil
+}
+
+func (p *fakeProvider) UnmarshalSession(data string) (goth.Session, error) {
+	var s fakeSession
+	err := json.Unmarshal([]byte(data), &s)
+
+	return &s, err
+}
+
+func (p *fakeProvider) FetchUser(goth.Session) (goth.User, error) { return p.user, nil }
+
+func (p *fakeProvider) RefreshToken(string) (*oauth2.Token, error) { return nil, nil }
+func (p *fakeProvider) RefreshTokenAvailable() bool                { return false }
+
+type fakeSession struct {
+	AuthURL string
+}
+
+func (s *fakeSession) GetAuthURL() (string, error) { return s.AuthURL, nil }
+
+func (s *fakeSession) Marshal() string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+func (s *fakeSession) Authorize(goth.Provider, goth.Params) (string, error) { return "", nil }
+
+func TestAuth(t *testing.T) {
+	h := apitest.New(t, map[string]any{"oauth.providers": []string{"fake"}})
+
+	avatarSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testImage(t, 64, 64))
+	}))
+	defer avatarSrv.Close()
+
+	goth.UseProviders(&fakeProvider{user: goth.User{
+		Email:     "dave@example.com",
+		Name:      "Dave",
+		AvatarURL: avatarSrv.URL + "/dave.png",
+	}})
+
+	state := beginLogin(t, h)
+
+	callback := "/api/v1/oauth2/callback/fake?state=" + state
+	cookie := http.Header{"Cookie": {"oauth_state=" + state}}
+
+	tests := []struct {
+		name   string
+		req    apitest.Request
+		status int
+	}{
+		{
+			name:   "login with disabled provider",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/oauth2/login/github"},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "callback without state cookie",
+			req:    apitest.Request{Method: http.MethodGet, Path: callback},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "callback with unknown state",
+			req:    apitest.Request{Method: http.MethodGet, Path: callback, Header: http.Header{"Cookie": {"oauth_state=unknown"}}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "callback with mismatching state",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/oauth2/callback/fake?state=other", Header: cookie},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid token",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/" + h.User("alice").ID.String() + "/tags", Token: "invalid"},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "token of other user",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/" + h.User("alice").ID.String() + "/tags", Token: h.Token(h.User("bob").ID)},
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := h.Do(tt.req)
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, resp.StatusCode, resp.Body)
+			}
+		})
+	}
+
+	// finish the login flow, the user is created and a JWT is issued
+	resp := h.Do(apitest.Request{Method: http.MethodGet, Path: callback, Header: cookie})
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("callback: expected status %d, got %d: %s", http.StatusFound, resp.StatusCode, resp.Body)
+	}
+
+	var token string
+	for _, c := range resp.Cookies() {
+		if c.Name == "briefkasten_jwt" {
+			token = strings.Trim(c.Value, `"`)
+		}
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return []byte(apitest.SigningKey), nil
+	}); err != nil {
+		t.Fatalf("callback: invalid JWT %q: %v", token, err)
+	}
+
+	// the issued token grants access to the API of the new user
+	resp = h.Do(apitest.Request{
+		Method: http.MethodGet,
+		Path:   "/api/v1/users/" + claims["user_id"].(string) + "/tags",
+		Token:  token,
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d with the issued token, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+	}
+
+	// the avatar of the provider has been imported
+	dave, err := h.Store.Users.GetByEmail(context.Background(), "dave@example.com")
+	if err != nil || !blob.IsRef(dave.Image.String) {
+		t.Errorf("expected an imported avatar, got %+v (%v)", dave, err)
+	}
+
+	// the provider session is indexed for the user under a new ID
+	if ids := sessionIDs(t, h, dave.ID); len(ids) != 1 || ids[0] == state || !h.Redis.Exists(string(redis.SessionID(ids[0]))) {
+		t.Errorf("expected one session of the user, got %v", ids)
+	}
+
+	// the state can not be used twice
+	resp = h.Do(apitest.Request{Method: http.MethodGet, Path: callback, Header: cookie})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("callback: expected status %d for a used state, got %d: %s", http.StatusBadRequest, resp.StatusCode, resp.Body)
+	}
+
+	t.Run("slow avatar", func(t *testing.T) {
+		release := make(chan struct{})
+		slowSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer slowSrv.Close()
+		defer close(release)
+
+		goth.UseProviders(&fakeProvider{user: goth.User{
+			Email:     "erin@example.com",
+			Name:      "Erin",
+			AvatarURL: slowSrv.URL + "/erin.png",
+		}})
+
+		// the login must not wait for the download timeout of the images
+		start := time.Now()
+		state := beginLogin(t, h)
+		resp := h.Do(apitest.Request{
+			Method: http.MethodGet,
+			Path:   "/api/v1/oauth2/callback/fake?state=" + state,
+			Header: http.Header{"Cookie": {"oauth_state=" + state}},
+		})
+		if resp.StatusCode != http.StatusFound {
+			t.Fatalf("callback: expected status %d, got %d: %s", http.StatusFound, resp.StatusCode, resp.Body)
+		}
+
+		if d := time.Since(start); d > 5*time.Second {
+			t.Errorf("expected the login to finish after the avatar import timeout, took %s", d)
+		}
+
+		erin, err := h.Store.Users.GetByEmail(context.Background(), "erin@example.com")
+		if err != nil || erin.Image.Valid {
+			t.Errorf("expected a user without avatar, got %+v (%v)", erin, err)
+		}
+	})
+
+	t.Run("disabled user", func(t *testing.T) {
+		bob := h.User("bob")
+		if err := h.Store.Users.SetDisabled(context.Background(), bob.ID, true); err != nil {
+			t.Fatal(err)
+		}
+
+		goth.UseProviders(&fakeProvider{user: goth.User{Email: bob.Email, Name: bob.Name}})
+
+		state := beginLogin(t, h)
+		resp := h.Do(apitest.Request{
+			Method: http.MethodGet,
+			Path:   "/api/v1/oauth2/callback/fake?state=" + state,
+			Header: http.Header{"Cookie": {"oauth_state=" + state}},
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("callback: expected status %d, got %d: %s", http.StatusForbidden, resp.StatusCode, resp.Body)
+		}
+
+		// tokens issued before the account has been disabled are rejected
+		resp = h.Do(apitest.Request{
+			Method: http.MethodGet,
+			Path:   "/api/v1/users/" + bob.ID.String() + "/tags",
+			Token:  h.Token(bob.ID),
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusForbidden, resp.StatusCode, resp.Body)
+		}
+	})
+}
+
+// beginLogin begins the login flow with the fake provider and returns the state,
+// which is stored in a cookie and redis.
+func beginLogin(t *testing.T, h *apitest.Harness) string {
+	t.Helper()
+
+	resp := h.Do(apitest.Request{Method: http.MethodGet, Path: "/api/v1/oauth2/login/fake"})
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("login: expected status %d, got %d: %s", http.StatusFound, resp.StatusCode, resp.Body)
+	}
+
+	var state string
+	for _, c := range resp.Cookies() {
+		if c.Name == "oauth_state" {
+			state = c.Value
+		}
+	}
+
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || state == "" || loc.Query().Get("state") != state {
+		t.Fatalf("login: expected a redirect with the state %q, got %q", state, resp.Header.Get("Location"))
+	}
+
+	return state
+}
+
+// login finishes the login flow with the fake provider as the user.
+func login(t *testing.T, h *apitest.Harness, u models.UserAccount) {
+	t.Helper()
+
+	goth.UseProviders(&fakeProvider{user: goth.User{Email: u.Email, Name: u.Name}})
+
+	state := beginLogin(t, h)
+	resp := h.Do(apitest.Request{
+		Method: http.MethodGet,
+		Path:   "/api/v1/oauth2/callback/fake?state=" + state,
+		Header: http.Header{"Cookie": {"oauth_state=" + state}},
+	})
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("callback: expected status %d, got %d: %s", http.StatusFound, resp.StatusCode, resp.Body)
+	}
+}
+
+// sessionIDs returns the IDs of all sessions indexed for the user.
+func sessionIDs(t *testing.T, h *apitest.Harness, id uuid.UUID) []string {
+	t.Helper()
+
+	key := string(redis.UserSessions(id))
+	if !h.Redis.Exists(key) {
+		return nil
+	}
+
+	ids, err := h.Redis.Members(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ids
+}
@@ -0,0 +1,82 @@
+package apiv1
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// GetBlob returns the blob of the ":key" path parameter.
+// The URL must be signed, see [blob.URL].
+func (s *Server) GetBlob(c *fiber.Ctx) error {
+	key := c.Params("key")
+	if !blob.ValidKey(key) {
+		return rerr.NotFound.WithLogMsg("invalid blob key")
+	}
+
+	expires, err := blob.Verify(config.C.Load(), key, c.Query("expires"), c.Query("signature"), time.Now())
+	if err != nil {
+		return rerr.PermissionDenied.With(err).WithLogMsg("invalid blob URL")
+	}
+
+	r, err := s.blobs.Get(ftracer.FromCtx(c), key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return rerr.NotFound.With(err).WithLogMsg("unknown blob")
+	} else if err != nil {
+		return rerr.InternalServerError.With(err).WithLogMsg("unable to retrieve blob")
+	}
+
+	// blobs are content-addressed and therefore never change,
+	// but the URL must not be used after it has expired
+	c.Set(fiber.HeaderCacheControl, "private, max-age="+strconv.Itoa(int(time.Until(expires).Seconds())))
+	c.Set(fiber.HeaderContentType, blob.ContentType(key))
+
+	return c.SendStream(r)
+}
+
+// requestImage returns the image of a request to store.
+//
+// Signed blob URLs, i.e. of an unchanged image of a previous response,
+// are converted back to their references, even if they have expired.
+// Blob references and blob URLs with an invalid signature are rejected,
+// so that users can not reference blobs they have never seen.
+func requestImage(image null.String) (null.String, error) {
+	if !image.Valid {
+		return image, nil
+	}
+
+	if ref, ok, err := blob.RefOfURL(config.C.Load(), image.String); err != nil {
+		return image, rerr.RequestMalformed.With(err).WithLogMsg("invalid blob URL as image")
+	} else if ok {
+		return null.StringFrom(ref), nil
+	}
+
+	if blob.IsRef(image.String) {
+		return image, rerr.RequestMalformed.WithLogMsg("blob references are not allowed as image")
+	}
+
+	return image, nil
+}
+
+// userResponse returns the user with the image resolved to a signed URL.
+func userResponse(u models.UserAccount) models.UserAccount {
+	u.Image = blob.Resolve(config.C.Load(), u.Image, time.Now())
+
+	return u
+}
+
+// bookmarkImage returns the bookmark with the image resolved to a signed URL.
+func bookmarkImage(cfg *config.Config, b models.Bookmark, now time.Time) models.Bookmark {
+	b.Image = blob.Resolve(cfg, b.Image, now)
+
+	return b
+}
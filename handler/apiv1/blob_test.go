@@ -0,0 +1,144 @@
+package apiv1_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/apiv1"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/apitest"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/images"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+func TestBookmarkImage(t *testing.T) {
+	h := apitest.New(t, nil)
+	ctx := context.Background()
+
+	imageSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/image.png" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write(testImage(t, 16, 16))
+	}))
+	defer imageSrv.Close()
+
+	alice := h.User("alice")
+	category := h.Row("Category.alice_reading").(*models.Category)
+	path := "/api/v1/users/" + alice.ID.String() + "/bookmarks"
+	token := h.Token(alice.ID)
+
+	create := func(image string) apitest.Response {
+		return h.Do(apitest.Request{Method: http.MethodPost, Path: path, Token: token, Body: map[string]any{
+			"category_id": category.ID,
+			"url":         "https://example.com/" + image,
+			"image":       image,
+		}})
+	}
+
+	// users can not reference blobs directly
+	key := blob.Key([]byte("secret"), ".png")
+	if err := h.Blobs.Put(ctx, key, []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, image := range []string{blob.Ref(key), strings.Replace(blob.URL(config.C.Load(), key, time.Now()), "signature=", "signature=00", 1)} {
+		if resp := create(image); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d: %s", image, http.StatusBadRequest, resp.StatusCode, resp.Body)
+		}
+	}
+
+	resp := create(imageSrv.URL + "/image.png")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+	}
+
+	var bookmark apiv1.BookmarkResponse
+	resp.Decode(t, &bookmark)
+
+	if resp := create(imageSrv.URL + "/missing.png"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+	}
+
+	res, err := images.ImportBookmarks(ctx, h.Store, h.Blobs)
+	if err != nil || res.Imported != 1 || res.Failed != 1 {
+		t.Fatalf("expected one imported and one failed image, got %+v (%v)", res, err)
+	}
+
+	stored, err := h.Store.Bookmarks.Get(ctx, alice.ID, bookmark.ID)
+	if err != nil || !blob.IsRef(stored.Image.String) {
+		t.Fatalf("expected a blob reference, got %q (%v)", stored.Image.String, err)
+	}
+
+	// the image is returned as signed URL
+	resp = h.Do(apitest.Request{Method: http.MethodGet, Path: path + "/" + bookmark.ID.String(), Token: token})
+	resp.Decode(t, &bookmark)
+
+	i := strings.Index(bookmark.Image.String, blob.PathPrefix)
+	if i < 0 {
+		t.Fatalf("expected a blob URL, got %q", bookmark.Image.String)
+	}
+
+	if resp := h.Do(apitest.Request{Method: http.MethodGet, Path: bookmark.Image.String[i:]}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("image: expected status %d, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+	}
+
+	// an unchanged URL is stored as reference again
+	resp = h.Do(apitest.Request{Method: http.MethodPut, Path: path + "/" + bookmark.ID.String(), Token: token, Body: map[string]any{
+		"category_id": category.ID,
+		"url":         bookmark.URL,
+		"image":       bookmark.Image.String,
+	}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("update: expected status %d, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+	}
+
+	if updated, err := h.Store.Bookmarks.Get(ctx, alice.ID, bookmark.ID); err != nil || updated.Image != stored.Image {
+		t.Fatalf("expected the image %q, got %q (%v)", stored.Image.String, updated.Image.String, err)
+	}
+
+	// only the unreferenced blob is deleted
+	gc, err := images.GC(ctx, h.Store, h.Blobs, -time.Hour, false)
+	if err != nil || gc.Scanned != 2 || gc.Deleted != 1 {
+		t.Fatalf("expected one deleted blob, got %+v (%v)", gc, err)
+	}
+
+	if _, err := h.Blobs.Get(ctx, key); err == nil {
+		t.Errorf("expected the unreferenced blob %s to be deleted", key)
+	}
+}
+
+func TestBookmarkImagePrivateNetwork(t *testing.T) {
+	h := apitest.New(t, map[string]any{"blob.allow_private_networks": false})
+
+	var requested bool
+	imageSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		w.Write(testImage(t, 16, 16))
+	}))
+	defer imageSrv.Close()
+
+	alice := h.User("alice")
+	category := h.Row("Category.alice_reading").(*models.Category)
+
+	resp := h.Do(apitest.Request{Method: http.MethodPost, Path: "/api/v1/users/" + alice.ID.String() + "/bookmarks", Token: h.Token(alice.ID), Body: map[string]any{
+		"category_id": category.ID,
+		"url":         "https://example.com",
+		"image":       imageSrv.URL + "/image.png",
+	}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+	}
+
+	res, err := images.ImportBookmarks(context.Background(), h.Store, h.Blobs)
+	if err != nil || res.Imported != 0 || res.Failed != 1 || requested {
+		t.Errorf("expected the import from a loopback address to be refused, got %+v (%v)", res, err)
+	}
+}
@@ -1 +1,282 @@
 package apiv1
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// BookmarkRequest is the request body to create or update a bookmark.
+type BookmarkRequest struct {
+	CategoryID uuid.UUID `json:"category_id"`
+	URL        string    `json:"url"`
+	// Image is the URL of the preview image.
+	// Remote images are imported into the blob storage in the background.
+	Image       null.String `json:"image"`
+	Description null.String `json:"description"`
+	// Tags are the names of the tags of the bookmark, missing tags are created.
+	// The tags are not changed on update if it is nil.
+	Tags []string `json:"tags"`
+}
+
+// BookmarkResponse is a bookmark including its tags.
+type BookmarkResponse struct {
+	models.Bookmark
+
+	Tags []models.Tag `json:"tags"`
+}
+
+// GetBookmarks returns all bookmarks of the user.
+func (s *Server) GetBookmarks(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	page, err := parsePage(c)
+	if err != nil {
+		return err
+	}
+
+	bookmarks, err := s.store.Bookmarks.List(ftracer.FromCtx(c), id, page)
+	if err != nil {
+		return storeErr(err, "unable to retrieve bookmarks")
+	}
+
+	cfg, now := config.C.Load(), time.Now()
+	for i, b := range bookmarks {
+		bookmarks[i] = bookmarkImage(cfg, b, now)
+	}
+
+	return c.JSON(bookmarks)
+}
+
+// GetBookmark returns a bookmark including its tags.
+func (s *Server) GetBookmark(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	bookmarkID, err := parseUUIDParam(c, "bookmark_id")
+	if err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("invalid bookmark ID")
+	}
+
+	ctx := ftracer.FromCtx(c)
+
+	bookmark, err := s.store.Bookmarks.Get(ctx, id, bookmarkID)
+	if err != nil {
+		return storeErr(err, "unable to retrieve bookmark")
+	}
+
+	resp, err := s.bookmarkResponse(ctx, bookmark)
+	if err != nil {
+		return storeErr(err, "unable to retrieve bookmark tags")
+	}
+
+	return c.JSON(resp)
+}
+
+// DeleteBookmark deletes a bookmark.
+func (s *Server) DeleteBookmark(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	bookmarkID, err := parseUUIDParam(c, "bookmark_id")
+	if err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("invalid bookmark ID")
+	}
+
+	if err := s.store.Bookmarks.Delete(ftracer.FromCtx(c), id, bookmarkID); err != nil {
+		return storeErr(err, "unable to delete bookmark")
+	}
+
+	return c.JSON(fiber.Map{"message": "Deleted"})
+}
+
+// UpdateBookmark updates a bookmark and replaces its tags.
+//
+// The handler runs in the request transaction, the bookmark is not
+// changed if one of the tags can not be created.
+func (s *Server) UpdateBookmark(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	bookmarkID, err := parseUUIDParam(c, "bookmark_id")
+	if err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("invalid bookmark ID")
+	}
+
+	var req BookmarkRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("unable to parse bookmark body")
+	}
+
+	image, err := requestImage(req.Image)
+	if err != nil {
+		return err
+	}
+
+	ctx := ftracer.FromCtx(c)
+
+	if req.CategoryID != uuid.Nil {
+		if _, err := s.store.Categories.Get(ctx, id, req.CategoryID); err != nil {
+			return storeErr(err, "unable to retrieve bookmark category")
+		}
+	}
+
+	bookmark := models.Bookmark{
+		ID:          bookmarkID,
+		UserID:      id,
+		CategoryID:  req.CategoryID,
+		URL:         req.URL,
+		Image:       image,
+		Description: req.Description,
+	}
+
+	if err := s.store.Bookmarks.Update(ctx, &bookmark); err != nil {
+		return storeErr(err, "unable to update bookmark")
+	}
+
+	if req.Tags != nil {
+		if err := s.setBookmarkTags(ctx, id, bookmarkID, req.Tags); err != nil {
+			return storeErr(err, "unable to update bookmark tags")
+		}
+	}
+
+	resp, err := s.bookmarkResponse(ctx, bookmark)
+	if err != nil {
+		return storeErr(err, "unable to retrieve bookmark tags")
+	}
+
+	return c.JSON(resp)
+}
+
+// CreateBookmark creates a new bookmark, including all missing tags.
+//
+// The handler runs in the request transaction, neither the bookmark
+// nor any tag is created if one of the steps fails.
+func (s *Server) CreateBookmark(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req BookmarkRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("unable to parse bookmark body")
+	}
+
+	if req.URL == "" {
+		return rerr.RequestMalformed.WithLogMsg("missing bookmark URL")
+	}
+
+	image, err := requestImage(req.Image)
+	if err != nil {
+		return err
+	}
+
+	ctx := ftracer.FromCtx(c)
+
+	// the category must belong to the user
+	if _, err := s.store.Categories.Get(ctx, id, req.CategoryID); err != nil {
+		return storeErr(err, "unable to retrieve bookmark category")
+	}
+
+	bookmark := models.Bookmark{
+		UserID:      id,
+		CategoryID:  req.CategoryID,
+		URL:         req.URL,
+		Image:       image,
+		Description: req.Description,
+	}
+
+	if err := s.store.Bookmarks.Create(ctx, &bookmark); err != nil {
+		return storeErr(err, "unable to create bookmark")
+	}
+
+	if err := s.setBookmarkTags(ctx, id, bookmark.ID, req.Tags); err != nil {
+		return storeErr(err, "unable to create bookmark tags")
+	}
+
+	resp, err := s.bookmarkResponse(ctx, bookmark)
+	if err != nil {
+		return storeErr(err, "unable to retrieve bookmark tags")
+	}
+
+	return c.JSON(resp)
+}
+
+// setBookmarkTags replaces the tags of the bookmark with the tags of the
+// given names. Missing tags of the user are created.
+func (s *Server) setBookmarkTags(ctx context.Context, userID, bookmarkID uuid.UUID, names []string) error {
+	ids := make([]uuid.UUID, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		tag, err := s.store.Tags.GetByName(ctx, userID, name)
+		if errors.Is(err, store.ErrNotFound) {
+			tag = models.Tag{UserID: userID, Name: name}
+			err = s.store.Tags.Create(ctx, &tag)
+		}
+		if err != nil {
+			return err
+		}
+
+		ids = append(ids, tag.ID)
+	}
+
+	return s.store.Bookmarks.SetTags(ctx, bookmarkID, ids)
+}
+
+// bookmarkResponse returns the bookmark including its tags.
+func (s *Server) bookmarkResponse(ctx context.Context, b models.Bookmark) (BookmarkResponse, error) {
+	resp := BookmarkResponse{
+		Bookmark: bookmarkImage(config.C.Load(), b, time.Now()),
+		Tags:     []models.Tag{},
+	}
+
+	ids, err := s.store.Bookmarks.TagIDs(ctx, b.ID)
+	if err != nil {
+		return resp, err
+	}
+
+	for _, tagID := range ids {
+		tag, err := s.store.Tags.Get(ctx, b.UserID, tagID)
+		if err != nil {
+			return resp, err
+		}
+
+		resp.Tags = append(resp.Tags, tag)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,218 @@
+package apiv1_test
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/apiv1"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/apitest"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+func TestBookmarks(t *testing.T) {
+	h := apitest.New(t, nil)
+
+	alice := h.User("alice")
+	aliceCategory := h.Row("Category.alice_reading").(*models.Category)
+	bobCategory := h.Row("Category.bob_work").(*models.Category)
+	aliceBookmark := h.Row("Bookmark.alice_blog").(*models.Bookmark)
+	bobBookmark := h.Row("Bookmark.bob_docs").(*models.Bookmark)
+
+	path := "/api/v1/users/" + alice.ID.String() + "/bookmarks"
+	token := h.Token(alice.ID)
+
+	tests := []struct {
+		name   string
+		req    apitest.Request
+		status int
+		check  func(t *testing.T, resp apitest.Response)
+	}{
+		{
+			name:   "list",
+			req:    apitest.Request{Method: http.MethodGet, Path: path, Token: token},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var bookmarks []models.Bookmark
+				resp.Decode(t, &bookmarks)
+
+				if len(bookmarks) != 1 || bookmarks[0].ID != aliceBookmark.ID {
+					t.Errorf("expected only the bookmark %s, got %+v", aliceBookmark.ID, bookmarks)
+				}
+			},
+		},
+		{
+			name:   "get",
+			req:    apitest.Request{Method: http.MethodGet, Path: path + "/" + aliceBookmark.ID.String(), Token: token},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var bookmark apiv1.BookmarkResponse
+				resp.Decode(t, &bookmark)
+
+				if got := tagNames(bookmark.Tags); len(got) != 1 || got[0] != "go" {
+					t.Errorf("expected the tag go, got %v", got)
+				}
+			},
+		},
+		{
+			name:   "get bookmark of other user",
+			req:    apitest.Request{Method: http.MethodGet, Path: path + "/" + bobBookmark.ID.String(), Token: token},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "create with new and existing tags",
+			req: apitest.Request{Method: http.MethodPost, Path: path, Token: token, Body: map[string]any{
+				"category_id": aliceCategory.ID,
+				"url":         "https://pkg.go.dev",
+				"tags":        []string{"go", "docs", "docs", " "},
+			}},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var bookmark apiv1.BookmarkResponse
+				resp.Decode(t, &bookmark)
+
+				if got := tagNames(bookmark.Tags); len(got) != 2 || got[0] != "docs" || got[1] != "go" {
+					t.Errorf("expected the tags docs and go, got %v", got)
+				}
+			},
+		},
+		{
+			name: "create in category of other user",
+			req: apitest.Request{Method: http.MethodPost, Path: path, Token: token, Body: map[string]any{
+				"category_id": bobCategory.ID,
+				"url":         "https://example.com",
+				"tags":        []string{"created-by-failed-request"},
+			}},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "create without URL",
+			req:    apitest.Request{Method: http.MethodPost, Path: path, Token: token, Body: map[string]any{"category_id": aliceCategory.ID}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "update replaces tags",
+			req: apitest.Request{Method: http.MethodPut, Path: path + "/" + aliceBookmark.ID.String(), Token: token, Body: map[string]any{
+				"description": "The Go Blog",
+				"tags":        []string{"blog"},
+			}},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var bookmark apiv1.BookmarkResponse
+				resp.Decode(t, &bookmark)
+
+				if bookmark.URL != aliceBookmark.URL || bookmark.Description.String != "The Go Blog" {
+					t.Errorf("unexpected bookmark %+v", bookmark.Bookmark)
+				}
+
+				if got := tagNames(bookmark.Tags); len(got) != 1 || got[0] != "blog" {
+					t.Errorf("expected the tag blog, got %v", got)
+				}
+			},
+		},
+		{
+			name: "update bookmark of other user",
+			req: apitest.Request{Method: http.MethodPut, Path: path + "/" + bobBookmark.ID.String(), Token: token, Body: map[string]any{
+				"url":  "https://example.com",
+				"tags": []string{"created-by-failed-request"},
+			}},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "delete bookmark of other user",
+			req:    apitest.Request{Method: http.MethodDelete, Path: path + "/" + bobBookmark.ID.String(), Token: token},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "delete",
+			req:    apitest.Request{Method: http.MethodDelete, Path: path + "/" + aliceBookmark.ID.String(), Token: token},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := h.Do(tt.req)
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, resp.StatusCode, resp.Body)
+			}
+
+			if tt.check != nil {
+				tt.check(t, resp)
+			}
+		})
+	}
+
+	// failed requests must be rolled back completely
+	_, err := h.Store.Tags.GetByName(context.Background(), alice.ID, "created-by-failed-request")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected the tag of the failed request to be rolled back, got %v", err)
+	}
+}
+
+func TestBookmarkCreateRollback(t *testing.T) {
+	h := apitest.New(t, nil)
+	ctx := context.Background()
+
+	alice := h.User("alice")
+	category := h.Row("Category.alice_reading").(*models.Category)
+
+	// linking the tags fails after the bookmark and its tags have been inserted
+	stmts := map[string][]string{
+		models.DialectSQLite: {
+			`CREATE TRIGGER fail_tag_on_bookmark BEFORE INSERT ON tag_on_bookmark
+			BEGIN SELECT RAISE(ABORT, 'injected failure'); END`,
+		},
+		models.DialectPostgres: {
+			`CREATE FUNCTION fail_tag_on_bookmark() RETURNS trigger AS $$
+			BEGIN RAISE EXCEPTION 'injected failure'; END $$ LANGUAGE plpgsql`,
+			`CREATE TRIGGER fail_tag_on_bookmark BEFORE INSERT ON tag_on_bookmark
+			FOR EACH ROW EXECUTE FUNCTION fail_tag_on_bookmark()`,
+		},
+	}
+
+	for _, stmt := range stmts[models.Dialect()] {
+		if _, err := h.DB.ExecContext(ctx, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp := h.Do(apitest.Request{Method: http.MethodPost, Path: "/api/v1/users/" + alice.ID.String() + "/bookmarks", Token: h.Token(alice.ID), Body: map[string]any{
+		"category_id": category.ID,
+		"url":         "https://example.com/rollback",
+		"tags":        []string{"rollback-a", "rollback-b"},
+	}})
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusInternalServerError, resp.StatusCode, resp.Body)
+	}
+
+	for _, name := range []string{"rollback-a", "rollback-b"} {
+		if _, err := h.Store.Tags.GetByName(ctx, alice.ID, name); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("expected the tag %s to be rolled back, got %v", name, err)
+		}
+	}
+
+	n, err := h.DB.NewSelect().
+		Model((*models.Bookmark)(nil)).
+		Where("url = ?", "https://example.com/rollback").
+		Count(ctx)
+	if err != nil || n != 0 {
+		t.Errorf("expected the bookmark to be rolled back, got %d rows (%v)", n, err)
+	}
+}
+
+func tagNames(tags []models.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,107 @@
+package apiv1
+
+import (
+	"time"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetCategories returns all categories of the user.
+func (s *Server) GetCategories(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	page, err := parsePage(c)
+	if err != nil {
+		return err
+	}
+
+	categories, err := s.store.Categories.List(ftracer.FromCtx(c), id, page)
+	if err != nil {
+		return storeErr(err, "unable to retrieve categories")
+	}
+
+	return c.JSON(categories)
+}
+
+// DeleteCategory deletes a category.
+func (s *Server) DeleteCategory(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	categoryID, err := parseUUIDParam(c, "category_id")
+	if err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("invalid category ID")
+	}
+
+	if err := s.store.Categories.Delete(ftracer.FromCtx(c), id, categoryID); err != nil {
+		return storeErr(err, "unable to delete category")
+	}
+
+	return c.JSON(fiber.Map{"message": "Deleted"})
+}
+
+// UpdateCategory updates a category.
+func (s *Server) UpdateCategory(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	categoryID, err := parseUUIDParam(c, "category_id")
+	if err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("invalid category ID")
+	}
+
+	var req models.Category
+
+	if err := c.BodyParser(&req); err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("unable to parse category body")
+	}
+
+	req.ID = categoryID
+	req.UserID = id
+
+	if err := s.store.Categories.Update(ftracer.FromCtx(c), &req); err != nil {
+		return storeErr(err, "unable to update category")
+	}
+
+	return c.JSON(req)
+}
+
+// CreateCategory creates a new category.
+func (s *Server) CreateCategory(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var category models.Category
+
+	if err := c.BodyParser(&category); err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("unable to parse category body")
+	}
+
+	if category.Name == "" {
+		return rerr.RequestMalformed.WithLogMsg("missing category name")
+	}
+
+	// the ID and creation time are set by the store
+	category.ID = uuid.Nil
+	category.CreatedAt = time.Time{}
+	category.UserID = id
+
+	if err := s.store.Categories.Create(ftracer.FromCtx(c), &category); err != nil {
+		return storeErr(err, "unable to create category")
+	}
+
+	return c.JSON(category)
+}
@@ -0,0 +1,106 @@
+package apiv1_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/apitest"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+func TestCategories(t *testing.T) {
+	h := apitest.New(t, nil)
+
+	alice := h.User("alice")
+	aliceCategory := h.Row("Category.alice_reading").(*models.Category)
+	bobCategory := h.Row("Category.bob_work").(*models.Category)
+
+	path := "/api/v1/users/" + alice.ID.String() + "/categories"
+	token := h.Token(alice.ID)
+
+	tests := []struct {
+		name   string
+		req    apitest.Request
+		status int
+		check  func(t *testing.T, resp apitest.Response)
+	}{
+		{
+			name:   "list",
+			req:    apitest.Request{Method: http.MethodGet, Path: path, Token: token},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var categories []models.Category
+				resp.Decode(t, &categories)
+
+				if len(categories) != 1 || categories[0].ID != aliceCategory.ID {
+					t.Errorf("expected only the category %s, got %+v", aliceCategory.ID, categories)
+				}
+			},
+		},
+		{
+			name:   "list without token",
+			req:    apitest.Request{Method: http.MethodGet, Path: path},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "create",
+			req:    apitest.Request{Method: http.MethodPost, Path: path, Token: token, Body: map[string]any{"name": "Cooking"}},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var category models.Category
+				resp.Decode(t, &category)
+
+				if category.Name != "Cooking" || category.UserID != alice.ID {
+					t.Errorf("unexpected category %+v", category)
+				}
+			},
+		},
+		{
+			name:   "create duplicate",
+			req:    apitest.Request{Method: http.MethodPost, Path: path, Token: token, Body: map[string]any{"name": aliceCategory.Name}},
+			status: http.StatusConflict,
+		},
+		{
+			name:   "create without name",
+			req:    apitest.Request{Method: http.MethodPost, Path: path, Token: token, Body: map[string]any{}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "update",
+			req:    apitest.Request{Method: http.MethodPut, Path: path + "/" + aliceCategory.ID.String(), Token: token, Body: map[string]any{"description": "Articles"}},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var category models.Category
+				resp.Decode(t, &category)
+
+				if category.Name != aliceCategory.Name || category.Description.String != "Articles" {
+					t.Errorf("unexpected category %+v", category)
+				}
+			},
+		},
+		{
+			name:   "update category of other user",
+			req:    apitest.Request{Method: http.MethodPut, Path: path + "/" + bobCategory.ID.String(), Token: token, Body: map[string]any{"name": "Stolen"}},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "delete category of other user",
+			req:    apitest.Request{Method: http.MethodDelete, Path: path + "/" + bobCategory.ID.String(), Token: token},
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := h.Do(tt.req)
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, resp.StatusCode, resp.Body)
+			}
+
+			if tt.check != nil {
+				tt.check(t, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package apiv1
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/text/language"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/middleware"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/avatar"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/images"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+const (
+	// maxNameLength is the maximum length of the name of a user in characters.
+	maxNameLength = 100
+	// avatarImportTimeout is the timeout for downloading the avatar of the
+	// OAuth provider, which must not delay the login.
+	avatarImportTimeout = 2 * time.Second
+)
+
+// ProfileRequest is the request body of the profile update.
+// Only the given fields are changed, an empty string clears the field.
+type ProfileRequest struct {
+	Name              *string `json:"name"`
+	PreferredLanguage *string `json:"preferred_language"`
+	Timezone          *string `json:"timezone"`
+	DefaultCategoryID *string `json:"default_category_id"`
+}
+
+// GetProfile returns the profile of the authenticated user.
+func (s *Server) GetProfile(c *fiber.Ctx) error {
+	u, ok := middleware.AccountFromCtx(c)
+	if !ok {
+		return rerr.Unauthenticated.WithLogMsg("missing user account")
+	}
+
+	return c.JSON(userResponse(u))
+}
+
+// UpdateProfile updates the profile of the authenticated user.
+func (s *Server) UpdateProfile(c *fiber.Ctx) error {
+	id, err := authUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req ProfileRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("unable to parse profile body")
+	}
+
+	ctx := ftracer.FromCtx(c)
+
+	p, err := req.update()
+	if err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("invalid profile")
+	}
+
+	if p.DefaultCategoryID != nil && p.DefaultCategoryID.Valid {
+		if _, err := s.store.Categories.Get(ctx, id, p.DefaultCategoryID.UUID); errors.Is(err, store.ErrNotFound) {
+			return rerr.RequestMalformed.With(err).WithLogMsg("unknown default category")
+		} else if err != nil {
+			return storeErr(err, "unable to retrieve default category")
+		}
+	}
+
+	u, err := s.store.Users.UpdateProfile(ctx, id, p)
+	if err != nil {
+		return storeErr(err, "unable to update profile")
+	}
+
+	return c.JSON(userResponse(u))
+}
+
+// UploadAvatar replaces the avatar of the authenticated user by the
+// uploaded image of the "avatar" form field.
+func (s *Server) UploadAvatar(c *fiber.Ctx) error {
+	id, err := authUserID(c)
+	if err != nil {
+		return err
+	}
+
+	fh, err := c.FormFile("avatar")
+	if err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("missing avatar file")
+	}
+
+	if fh.Size > avatar.MaxFileSize {
+		return rerr.RequestMalformed.WithLogMsg("avatar file is too large")
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return rerr.InternalServerError.With(err).WithLogMsg("unable to open avatar file")
+	}
+	defer f.Close()
+
+	data, err := avatar.Convert(f)
+	if errors.Is(err, avatar.ErrInvalidImage) {
+		return rerr.RequestMalformed.With(err).WithLogMsg("invalid avatar image")
+	} else if err != nil {
+		return rerr.InternalServerError.With(err).WithLogMsg("unable to convert avatar")
+	}
+
+	u, err := s.setAvatar(c, id, data)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(userResponse(u))
+}
+
+// DeleteAvatar removes the avatar of the authenticated user.
+func (s *Server) DeleteAvatar(c *fiber.Ctx) error {
+	id, err := authUserID(c)
+	if err != nil {
+		return err
+	}
+
+	u, err := s.store.Users.UpdateProfile(ftracer.FromCtx(c), id, store.ProfileUpdate{Image: &null.String{}})
+	if err != nil {
+		return storeErr(err, "unable to delete avatar")
+	}
+
+	return c.JSON(userResponse(u))
+}
+
+// importAvatar imports the avatar of the OAuth provider, if the user has none.
+// Errors and timeouts are only logged, since the avatar is not required to log in.
+func (s *Server) importAvatar(c *fiber.Ctx, u models.UserAccount, url string) models.UserAccount {
+	if u.Image.Valid || url == "" {
+		return u
+	}
+
+	ctx, cancel := context.WithTimeout(ftracer.FromCtx(c), avatarImportTimeout)
+	defer cancel()
+
+	data, _, err := images.Download(ctx, url, avatar.MaxFileSize)
+	if err == nil {
+		data, err = avatar.Convert(bytes.NewReader(data))
+	}
+	if err != nil {
+		log.Warn("Unable to import avatar", zap.Error(err), zap.Stringer("user_id", u.ID))
+		return u
+	}
+
+	updated, err := s.setAvatar(c, u.ID, data)
+	if err != nil {
+		log.Warn("Unable to store imported avatar", zap.Error(err), zap.Stringer("user_id", u.ID))
+		return u
+	}
+
+	return updated
+}
+
+// setAvatar stores the avatar and sets the image of the user to its reference.
+func (s *Server) setAvatar(c *fiber.Ctx, id uuid.UUID, data []byte) (models.UserAccount, error) {
+	ctx := ftracer.FromCtx(c)
+	key := blob.Key(data, avatar.Ext)
+
+	if err := s.blobs.Put(ctx, key, data); err != nil {
+		return models.UserAccount{}, rerr.InternalServerError.With(err).WithLogMsg("unable to store avatar")
+	}
+
+	image := null.StringFrom(blob.Ref(key))
+
+	u, err := s.store.Users.UpdateProfile(ctx, id, store.ProfileUpdate{Image: &image})
+	if err != nil {
+		return u, storeErr(err, "unable to update avatar")
+	}
+
+	return u, nil
+}
+
+// update validates the request and returns the profile update.
+func (r ProfileRequest) update() (store.ProfileUpdate, error) {
+	var p store.ProfileUpdate
+
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+			return p, errors.Errorf("name must have between 1 and %d characters", maxNameLength)
+		}
+
+		p.Name = &name
+	}
+
+	if r.PreferredLanguage != nil {
+		var lang null.String
+
+		if *r.PreferredLanguage != "" {
+			tag, err := language.Parse(*r.PreferredLanguage)
+			if err != nil {
+				return p, errors.Wrap(err, "invalid preferred language")
+			}
+
+			lang = null.StringFrom(tag.String())
+		}
+
+		p.PreferredLanguage = &lang
+	}
+
+	if r.Timezone != nil {
+		var tz null.String
+
+		if *r.Timezone != "" {
+			// "Local" is the time zone of the server
+			if _, err := time.LoadLocation(*r.Timezone); err != nil || *r.Timezone == "Local" {
+				return p, errors.Errorf("invalid timezone %q", *r.Timezone)
+			}
+
+			tz = null.StringFrom(*r.Timezone)
+		}
+
+		p.Timezone = &tz
+	}
+
+	if r.DefaultCategoryID != nil {
+		var id uuid.NullUUID
+
+		if *r.DefaultCategoryID != "" {
+			parsed, err := uuid.Parse(*r.DefaultCategoryID)
+			if err != nil {
+				return p, errors.Wrap(err, "invalid default category ID")
+			}
+
+			id = uuid.NullUUID{UUID: parsed, Valid: true}
+		}
+
+		p.DefaultCategoryID = &id
+	}
+
+	return p, nil
+}
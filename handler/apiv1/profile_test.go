@@ -0,0 +1,228 @@
+package apiv1_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/apitest"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+func TestProfile(t *testing.T) {
+	h := apitest.New(t, nil)
+
+	alice := h.User("alice")
+	token := h.Token(alice.ID)
+	aliceReading := h.Row("Category.alice_reading").(*models.Category)
+	bobWork := h.Row("Category.bob_work").(*models.Category)
+
+	tests := []struct {
+		name   string
+		req    apitest.Request
+		status int
+		check  func(t *testing.T, u models.UserAccount)
+	}{
+		{
+			name:   "get",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/me", Token: token},
+			status: http.StatusOK,
+			check: func(t *testing.T, u models.UserAccount) {
+				if u.ID != alice.ID || u.Email != alice.Email {
+					t.Errorf("expected alice, got %+v", u)
+				}
+			},
+		},
+		{
+			name: "update",
+			req: apitest.Request{
+				Method: http.MethodPatch,
+				Path:   "/api/v1/users/me",
+				Token:  token,
+				Body: map[string]any{
+					"name":                " Alice Liddell ",
+					"preferred_language":  "de-de",
+					"timezone":            "Europe/Berlin",
+					"default_category_id": aliceReading.ID,
+				},
+			},
+			status: http.StatusOK,
+			check: func(t *testing.T, u models.UserAccount) {
+				if u.Name != "Alice Liddell" || u.PreferredLanguage.String != "de-DE" || u.Timezone.String != "Europe/Berlin" ||
+					u.DefaultCategoryID.UUID != aliceReading.ID || u.Email != alice.Email {
+					t.Errorf("unexpected profile %+v", u)
+				}
+			},
+		},
+		{
+			name:   "partial update",
+			req:    apitest.Request{Method: http.MethodPatch, Path: "/api/v1/users/me", Token: token, Body: map[string]any{"timezone": ""}},
+			status: http.StatusOK,
+			check: func(t *testing.T, u models.UserAccount) {
+				if u.Timezone.Valid || u.Name != "Alice Liddell" || !u.DefaultCategoryID.Valid {
+					t.Errorf("expected only the timezone to be cleared, got %+v", u)
+				}
+			},
+		},
+		{
+			name:   "empty name",
+			req:    apitest.Request{Method: http.MethodPatch, Path: "/api/v1/users/me", Token: token, Body: map[string]any{"name": " "}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid language",
+			req:    apitest.Request{Method: http.MethodPatch, Path: "/api/v1/users/me", Token: token, Body: map[string]any{"preferred_language": "not a language"}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid timezone",
+			req:    apitest.Request{Method: http.MethodPatch, Path: "/api/v1/users/me", Token: token, Body: map[string]any{"timezone": "Mars/Olympus_Mons"}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "category of other user",
+			req:    apitest.Request{Method: http.MethodPatch, Path: "/api/v1/users/me", Token: token, Body: map[string]any{"default_category_id": bobWork.ID}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "without token",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/me"},
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := h.Do(tt.req)
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, resp.StatusCode, resp.Body)
+			}
+
+			if tt.check != nil {
+				var u models.UserAccount
+				resp.Decode(t, &u)
+
+				tt.check(t, u)
+			}
+		})
+	}
+}
+
+func TestAvatar(t *testing.T) {
+	h := apitest.New(t, nil)
+
+	alice := h.User("alice")
+	token := h.Token(alice.ID)
+
+	resp := h.Do(uploadAvatar(t, token, []byte("not an image")))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("invalid image: expected status %d, got %d: %s", http.StatusBadRequest, resp.StatusCode, resp.Body)
+	}
+
+	resp = h.Do(uploadAvatar(t, token, testImage(t, 300, 200)))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+	}
+
+	var u models.UserAccount
+	resp.Decode(t, &u)
+
+	i := strings.Index(u.Image.String, "/api/v1/blobs/")
+	if i < 0 {
+		t.Fatalf("expected a blob URL, got %q", u.Image.String)
+	}
+
+	// the avatar is served without authentication, but requires the signature
+	unsigned, _, _ := strings.Cut(u.Image.String[i:], "?")
+	resp = h.Do(apitest.Request{Method: http.MethodGet, Path: unsigned})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unsigned avatar: expected status %d, got %d: %s", http.StatusForbidden, resp.StatusCode, resp.Body)
+	}
+
+	resp = h.Do(apitest.Request{Method: http.MethodGet, Path: u.Image.String[i:]})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("avatar: expected status %d, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+	}
+
+	img, err := png.Decode(bytes.NewReader(resp.Body))
+	if err != nil {
+		t.Fatalf("avatar is no PNG image: %v", err)
+	}
+
+	if b := img.Bounds(); b.Dx() != 256 || b.Dy() != 256 {
+		t.Errorf("expected a 256x256 avatar, got %s", b)
+	}
+
+	resp = h.Do(apitest.Request{Method: http.MethodDelete, Path: "/api/v1/users/me/avatar", Token: token})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("delete: expected status %d, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+	}
+
+	resp.Decode(t, &u)
+	if u.Image.Valid {
+		t.Errorf("expected no image, got %q", u.Image.String)
+	}
+
+	unknown := blob.URL(config.C.Load(), strings.Repeat("0", 64)+".png", time.Now())
+	resp = h.Do(apitest.Request{Method: http.MethodGet, Path: unknown[strings.Index(unknown, "/api/v1/blobs/"):]})
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown blob: expected status %d, got %d: %s", http.StatusNotFound, resp.StatusCode, resp.Body)
+	}
+}
+
+// uploadAvatar returns the request uploading the avatar.
+func uploadAvatar(t *testing.T, token string, data []byte) apitest.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	fw, err := w.CreateFormFile("avatar", "avatar.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return apitest.Request{
+		Method:  http.MethodPut,
+		Path:    "/api/v1/users/me/avatar",
+		Token:   token,
+		RawBody: body.Bytes(),
+		Header:  http.Header{"Content-Type": {w.FormDataContentType()}},
+	}
+}
+
+// testImage returns a PNG image of the given size.
+func testImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,111 @@
+package apiv1
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/middleware"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+)
+
+// Server holds the dependencies of the v1 API handlers.
+type Server struct {
+	store store.Store
+	blobs blob.Store
+}
+
+// NewServer returns a new server using the given stores.
+func NewServer(s store.Store, blobs blob.Store) *Server {
+	return &Server{store: s, blobs: blobs}
+}
+
+// authUserID returns the ID of the authenticated user.
+func authUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.Parse(middleware.UserIDFromCtx(c))
+	if err != nil {
+		return uuid.Nil, rerr.Unauthenticated.With(err).WithLogMsg("invalid user ID in JWT claims")
+	}
+
+	return id, nil
+}
+
+// pathUserID returns the ID of the authenticated user, which must be
+// the user of the ":id" path parameter.
+func pathUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := authUserID(c)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	pathID, err := parseUUIDParam(c, "id")
+	if err != nil {
+		return uuid.Nil, rerr.RequestMalformed.With(err).WithLogMsg("invalid user ID")
+	}
+
+	if id != pathID {
+		return uuid.Nil, rerr.RequestMalformed.WithLogMsg("missmatching user IDs")
+	}
+
+	return id, nil
+}
+
+func parseUUIDParam(c *fiber.Ctx, param string) (uuid.UUID, error) {
+	str := c.Params(param)
+	if str == "" {
+		return uuid.Nil, rerr.RequestMalformed.WithLogMsg("missing ID parameter")
+	}
+
+	id, err := uuid.Parse(str)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+// parsePage returns the page of the query parameters "after_id",
+// "after" (RFC 3339 timestamp) and "limit".
+func parsePage(c *fiber.Ctx) (store.Page, error) {
+	var (
+		page store.Page
+		err  error
+	)
+
+	if v := c.Query("after_id"); v != "" {
+		if page.AfterID, err = uuid.Parse(v); err != nil {
+			return page, rerr.RequestMalformed.With(err).WithLogMsg("invalid after_id parameter")
+		}
+	}
+
+	if v := c.Query("after"); v != "" {
+		if page.AfterTime, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			return page, rerr.RequestMalformed.With(err).WithLogMsg("invalid after parameter")
+		}
+	}
+
+	if v := c.Query("limit"); v != "" {
+		if page.Limit, err = strconv.Atoi(v); err != nil || page.Limit < 0 || page.Limit > store.DefaultPageSize {
+			return page, rerr.RequestMalformed.With(err).WithLogMsg("invalid limit parameter")
+		}
+	}
+
+	return page, nil
+}
+
+// storeErr converts the error of a store to a rerr error.
+func storeErr(err error, logMsg string) error {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return rerr.NotFound.With(err).WithLogMsg(logMsg)
+	case errors.Is(err, store.ErrConflict):
+		return rerr.Conflict.With(err).WithLogMsg(logMsg)
+	default:
+		return rerr.InternalServerError.With(err).WithLogMsg(logMsg)
+	}
+}
@@ -7,66 +7,54 @@ import (
 	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
 	"github.com/fabmation-gmbh/briefkasten-go/models"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 )
 
 // GetTags returns all tags the user has access to.
-func GetTags(c *fiber.Ctx) error {
-	user := c.Locals("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	id := claims["user_id"].(uuid.UUID)
-	ctx := ftracer.FromCtx(c)
+func (s *Server) GetTags(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
 
-	// TODO: Implement pagination
-	var startTime time.Time
+	page, err := parsePage(c)
+	if err != nil {
+		return err
+	}
 
-	tags, err := models.GetTagsByUserID(ctx, id, uuid.Nil, startTime, 100)
+	tags, err := s.store.Tags.List(ftracer.FromCtx(c), id, page)
 	if err != nil {
-		return rerr.InternalServerError.With(err).WithLogMsg("unable to retrieve tags")
+		return storeErr(err, "unable to retrieve tags")
 	}
 
 	return c.JSON(tags)
 }
 
-type TagDeleteRequest struct {
-	ID     uuid.UUID `json:"id"`
-	UserID uuid.UUID `json:"user_id"`
-}
-
 // DeleteTag deletes a tag.
-func DeleteTag(c *fiber.Ctx) error {
-	// TODO: Use Params
-
-	user := c.Locals("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	id := claims["user_id"].(uuid.UUID)
-	ctx := ftracer.FromCtx(c)
-
-	var req TagDeleteRequest
-
-	if err := c.BodyParser(&req); err != nil {
-		return rerr.RequestMalformed.With(err).WithLogMsg("unable to parse body")
+func (s *Server) DeleteTag(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
 	}
 
-	if id != req.UserID {
-		return rerr.RequestMalformed.WithLogMsg("missmatching user IDs")
+	tagID, err := parseUUIDParam(c, "tag_id")
+	if err != nil {
+		return rerr.RequestMalformed.With(err).WithLogMsg("invalid tag ID")
 	}
 
-	if err := models.DeleteTag(ctx, id, req.UserID); err != nil {
-		return rerr.InternalServerError.With(err).WithLogMsg("unable to delete tag")
+	if err := s.store.Tags.Delete(ftracer.FromCtx(c), id, tagID); err != nil {
+		return storeErr(err, "unable to delete tag")
 	}
 
 	return c.JSON(fiber.Map{"message": "Deleted"})
 }
 
 // UpdateTag updates a tag.
-func UpdateTag(c *fiber.Ctx) error {
-	// TODO: Use Params (user)
-	user := c.Locals("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	id := claims["user_id"].(uuid.UUID)
-	ctx := ftracer.FromCtx(c)
+func (s *Server) UpdateTag(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
 
 	tagID, err := parseUUIDParam(c, "tag_id")
 	if err != nil {
@@ -82,24 +70,19 @@ func UpdateTag(c *fiber.Ctx) error {
 	req.ID = tagID
 	req.UserID = id
 
-	if id != req.UserID {
-		return rerr.RequestMalformed.WithLogMsg("missmatching user IDs")
-	}
-
-	if err := req.Update(ctx); err != nil {
-		return rerr.InternalServerError.With(err).WithLogMsg("unable to update tag")
+	if err := s.store.Tags.Update(ftracer.FromCtx(c), &req); err != nil {
+		return storeErr(err, "unable to update tag")
 	}
 
 	return c.JSON(req)
 }
 
 // CreateTag creates a new tag.
-func CreateTag(c *fiber.Ctx) error {
-	// TODO: Use Params
-	user := c.Locals("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	id := claims["user_id"].(uuid.UUID)
-	ctx := ftracer.FromCtx(c)
+func (s *Server) CreateTag(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var tag models.Tag
 
@@ -107,30 +90,22 @@ func CreateTag(c *fiber.Ctx) error {
 		return rerr.RequestMalformed.With(err).WithLogMsg("unable to parse tag body")
 	}
 
-	if id != tag.UserID {
+	if tag.UserID != uuid.Nil && id != tag.UserID {
 		return rerr.RequestMalformed.WithLogMsg("missmatching user IDs")
 	}
 
-	tag.UserID = id
-	tag.CreatedAt = time.Now()
-
-	if err := tag.Create(ctx); err != nil {
-		return rerr.InternalServerError.With(err).WithLogMsg("unable to create tag")
+	if tag.Name == "" {
+		return rerr.RequestMalformed.WithLogMsg("missing tag name")
 	}
 
-	return c.JSON(tag)
-}
-
-func parseUUIDParam(c *fiber.Ctx, param string) (uuid.UUID, error) {
-	str := c.Params(param)
-	if str == "" {
-		return uuid.Nil, rerr.RequestMalformed.WithLogMsg("missing ID parameter")
-	}
+	// the ID and creation time are set by the store
+	tag.ID = uuid.Nil
+	tag.CreatedAt = time.Time{}
+	tag.UserID = id
 
-	id, err := uuid.Parse(str)
-	if err != nil {
-		return uuid.Nil, err
+	if err := s.store.Tags.Create(ftracer.FromCtx(c), &tag); err != nil {
+		return storeErr(err, "unable to create tag")
 	}
 
-	return id, nil
+	return c.JSON(tag)
 }
@@ -0,0 +1,144 @@
+package apiv1_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/apitest"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+func TestTags(t *testing.T) {
+	h := apitest.New(t, nil)
+
+	alice := h.User("alice")
+	bob := h.User("bob")
+	aliceTag := h.Row("Tag.alice_go").(*models.Tag)
+	bobTag := h.Row("Tag.bob_rust").(*models.Tag)
+
+	tests := []struct {
+		name   string
+		req    apitest.Request
+		status int
+		check  func(t *testing.T, resp apitest.Response)
+	}{
+		{
+			name:   "list",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/" + alice.ID.String() + "/tags", Token: h.Token(alice.ID)},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var tags []models.Tag
+				resp.Decode(t, &tags)
+
+				if len(tags) != 1 || tags[0].ID != aliceTag.ID {
+					t.Errorf("expected only the tag %s, got %+v", aliceTag.ID, tags)
+				}
+			},
+		},
+		{
+			name:   "list of other user",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/" + bob.ID.String() + "/tags", Token: h.Token(alice.ID)},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "list with invalid limit",
+			req:    apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/" + alice.ID.String() + "/tags?limit=abc", Token: h.Token(alice.ID)},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "create",
+			req: apitest.Request{
+				Method: http.MethodPost,
+				Path:   "/api/v1/users/" + alice.ID.String() + "/tags",
+				Token:  h.Token(alice.ID),
+				Body:   map[string]any{"name": "sql", "emoji": "🗄"},
+			},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var tag models.Tag
+				resp.Decode(t, &tag)
+
+				if tag.Name != "sql" || tag.UserID != alice.ID || tag.ID == aliceTag.ID {
+					t.Errorf("unexpected tag %+v", tag)
+				}
+			},
+		},
+		{
+			name: "create duplicate",
+			req: apitest.Request{
+				Method: http.MethodPost,
+				Path:   "/api/v1/users/" + alice.ID.String() + "/tags",
+				Token:  h.Token(alice.ID),
+				Body:   map[string]any{"name": aliceTag.Name},
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "create without name",
+			req: apitest.Request{
+				Method: http.MethodPost,
+				Path:   "/api/v1/users/" + alice.ID.String() + "/tags",
+				Token:  h.Token(alice.ID),
+				Body:   map[string]any{"description": "no name"},
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "update",
+			req: apitest.Request{
+				Method: http.MethodPut,
+				Path:   "/api/v1/users/" + alice.ID.String() + "/tags/" + aliceTag.ID.String(),
+				Token:  h.Token(alice.ID),
+				Body:   map[string]any{"name": "golang"},
+			},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp apitest.Response) {
+				var tag models.Tag
+				resp.Decode(t, &tag)
+
+				if tag.Name != "golang" || tag.ID != aliceTag.ID {
+					t.Errorf("unexpected tag %+v", tag)
+				}
+			},
+		},
+		{
+			name: "update tag of other user",
+			req: apitest.Request{
+				Method: http.MethodPut,
+				Path:   "/api/v1/users/" + alice.ID.String() + "/tags/" + bobTag.ID.String(),
+				Token:  h.Token(alice.ID),
+				Body:   map[string]any{"name": "stolen"},
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "delete with invalid ID",
+			req:    apitest.Request{Method: http.MethodDelete, Path: "/api/v1/users/" + alice.ID.String() + "/tags/abc", Token: h.Token(alice.ID)},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "delete tag of other user",
+			req:    apitest.Request{Method: http.MethodDelete, Path: "/api/v1/users/" + alice.ID.String() + "/tags/" + bobTag.ID.String(), Token: h.Token(alice.ID)},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "delete",
+			req:    apitest.Request{Method: http.MethodDelete, Path: "/api/v1/users/" + alice.ID.String() + "/tags/" + aliceTag.ID.String(), Token: h.Token(alice.ID)},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := h.Do(tt.req)
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, resp.StatusCode, resp.Body)
+			}
+
+			if tt.check != nil {
+				tt.check(t, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package apiv1
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/audit"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/export"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/redis"
+)
+
+// DeleteUser deletes the account of the authenticated user including all of their data.
+func (s *Server) DeleteUser(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := ftracer.FromCtx(c)
+
+	if err := s.store.Users.Delete(ctx, id); err != nil {
+		return storeErr(err, "unable to delete user")
+	}
+
+	// the account is already deleted, stale sessions are not worth failing the request
+	purgeSessions(ctx, id)
+
+	audit.Record(ctx, audit.Entry{
+		Actor:    "user:" + id.String(),
+		Action:   "user.delete",
+		OldValue: id,
+	})
+
+	c.ClearCookie("briefkasten_jwt")
+
+	return c.JSON(fiber.Map{"message": "Deleted"})
+}
+
+// ExportUser returns a zip archive with all data of the authenticated user.
+func (s *Server) ExportUser(c *fiber.Ctx) error {
+	id, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := ftracer.FromCtx(c)
+
+	archive, err := export.Collect(ctx, s.store, id)
+	if err != nil {
+		return storeErr(err, "unable to collect user data")
+	}
+
+	var buf bytes.Buffer
+	if err := archive.WriteZip(ctx, &buf, s.blobs); err != nil {
+		return rerr.InternalServerError.With(err).WithLogMsg("unable to write data archive")
+	}
+
+	c.Attachment("briefkasten-export-" + time.Now().UTC().Format("20060102") + ".zip")
+	c.Set(fiber.HeaderContentType, "application/zip")
+
+	return c.Send(buf.Bytes())
+}
+
+// purgeSessions deletes all sessions of the user, see [redis.PurgeUser].
+// Errors are only logged, since all tokens of the user are already rejected.
+func purgeSessions(ctx context.Context, id uuid.UUID) {
+	if err := redis.PurgeUser(ctx, id); err != nil {
+		log.Error("Unable to purge user sessions", zap.Error(err), zap.Stringer("user_id", id))
+	}
+}
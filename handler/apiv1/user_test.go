@@ -0,0 +1,171 @@
+package apiv1_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/apitest"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/export"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/redis"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+func TestExportUser(t *testing.T) {
+	h := apitest.New(t, nil)
+
+	alice := h.User("alice")
+	bob := h.User("bob")
+
+	ses := models.Session{Token: "secret", UserID: alice.ID, Expires: time.Now().Add(time.Hour).UTC()}
+	if err := ses.Create(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	avatar := blob.Key([]byte("avatar"), ".png")
+	if err := h.Blobs.Put(context.Background(), avatar, []byte("avatar")); err != nil {
+		t.Fatal(err)
+	}
+
+	image := null.StringFrom(blob.Ref(avatar))
+	if _, err := h.Store.Users.UpdateProfile(context.Background(), alice.ID, store.ProfileUpdate{Image: &image}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := h.Do(apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/" + bob.ID.String() + "/export", Token: h.Token(alice.ID)})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("export of other user: expected status %d, got %d: %s", http.StatusBadRequest, resp.StatusCode, resp.Body)
+	}
+
+	resp = h.Do(apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/" + alice.ID.String() + "/export", Token: h.Token(alice.ID)})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(resp.Body), int64(len(resp.Body)))
+	if err != nil {
+		t.Fatalf("invalid zip archive: %v", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(r); err != nil {
+			t.Fatal(err)
+		}
+		r.Close()
+
+		files[f.Name] = buf.Bytes()
+	}
+
+	var (
+		account   models.UserAccount
+		bookmarks []export.Bookmark
+		sessions  []map[string]any
+	)
+
+	for name, v := range map[string]any{
+		"user_account.json": &account,
+		"bookmarks.json":    &bookmarks,
+		"sessions.json":     &sessions,
+		"categories.json":   &[]models.Category{},
+		"tags.json":         &[]models.Tag{},
+	} {
+		if err := json.Unmarshal(files[name], v); err != nil {
+			t.Fatalf("invalid %s %q: %v", name, files[name], err)
+		}
+	}
+
+	aliceBlog := h.Row("Bookmark.alice_blog").(*models.Bookmark)
+	aliceGo := h.Row("Tag.alice_go").(*models.Tag)
+
+	if account.ID != alice.ID {
+		t.Errorf("expected account %s, got %+v", alice.ID, account)
+	}
+
+	if len(bookmarks) != 1 || bookmarks[0].ID != aliceBlog.ID || len(bookmarks[0].TagIDs) != 1 || bookmarks[0].TagIDs[0] != aliceGo.ID {
+		t.Errorf("expected only the bookmark %s with tag %s, got %+v", aliceBlog.ID, aliceGo.ID, bookmarks)
+	}
+
+	if len(sessions) != 1 || sessions[0]["token"] != nil {
+		t.Errorf("expected one session without token, got %+v", sessions)
+	}
+
+	if account.Image.String != blob.Ref(avatar) || string(files["blobs/"+avatar]) != "avatar" {
+		t.Errorf("expected the avatar %s in the archive, got %q", account.Image.String, files["blobs/"+avatar])
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	h := apitest.New(t, map[string]any{"oauth.providers": []string{"fake"}})
+	ctx := context.Background()
+
+	alice := h.User("alice")
+	bob := h.User("bob")
+	token := h.Token(alice.ID)
+
+	// the sessions of bob must be kept
+	login(t, h, alice)
+	login(t, h, alice)
+	login(t, h, bob)
+
+	aliceSessions := sessionIDs(t, h, alice.ID)
+	bobSessions := sessionIDs(t, h, bob.ID)
+	if len(aliceSessions) != 2 || len(bobSessions) != 1 {
+		t.Fatalf("expected 2 sessions of alice and 1 of bob, got %v and %v", aliceSessions, bobSessions)
+	}
+
+	resp := h.Do(apitest.Request{Method: http.MethodDelete, Path: "/api/v1/users/" + bob.ID.String(), Token: token})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("delete other user: expected status %d, got %d: %s", http.StatusBadRequest, resp.StatusCode, resp.Body)
+	}
+
+	resp = h.Do(apitest.Request{Method: http.MethodDelete, Path: "/api/v1/users/" + alice.ID.String(), Token: token})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+	}
+
+	if _, err := h.Store.Users.Get(ctx, alice.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected deleted user, got %v", err)
+	}
+
+	if counts, err := h.Store.Users.Counts(ctx, bob.ID); err != nil || counts.Bookmarks != 1 {
+		t.Errorf("expected the data of bob to be kept, got %+v (%v)", counts, err)
+	}
+
+	for _, sesID := range aliceSessions {
+		if key := string(redis.SessionID(sesID)); h.Redis.Exists(key) {
+			t.Errorf("expected the session %s of alice to be purged", key)
+		}
+	}
+
+	for _, sesID := range bobSessions {
+		if key := string(redis.SessionID(sesID)); !h.Redis.Exists(key) {
+			t.Errorf("expected the session %s of bob to be kept", key)
+		}
+	}
+
+	if key := string(redis.UserSessions(alice.ID)); h.Redis.Exists(key) {
+		t.Errorf("expected the session index %s to be purged", key)
+	}
+
+	// the token of the deleted user is rejected
+	resp = h.Do(apitest.Request{Method: http.MethodGet, Path: "/api/v1/users/" + alice.ID.String() + "/tags", Token: token})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusBadRequest, resp.StatusCode, resp.Body)
+	}
+}
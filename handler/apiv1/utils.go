@@ -15,7 +15,7 @@ func storeOAuthCookie(c *fiber.Ctx, name, value string) {
 		Name:     name,
 		Value:    value,
 		MaxAge:   int(time.Hour.Seconds()),
-		Secure:   config.C.General.SecureCookie,
+		Secure:   config.C.Load().General.SecureCookie,
 		HTTPOnly: true,
 	})
 }
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/images"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+)
+
+// stopBlobMaintenance stops the blob maintenance.
+var stopBlobMaintenance context.CancelFunc = func() {}
+
+// startBlobMaintenance periodically imports the remote bookmark images
+// and removes all unreferenced blobs.
+func startBlobMaintenance(s store.Store, blobs blob.Store) {
+	cfg := config.C.Load()
+	if cfg.Blob.MaintenanceInterval == 0 {
+		log.Debug("Blob maintenance is disabled")
+		return
+	}
+
+	var ctx context.Context
+	ctx, stopBlobMaintenance = context.WithCancel(context.Background())
+
+	go func() {
+		ticker := time.NewTicker(cfg.Blob.MaintenanceInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := images.Maintain(ctx, s, blobs, config.C.Load().Blob.GCMinAge); err != nil {
+				log.Warn("Blob maintenance failed", zap.Error(err))
+			}
+		}
+	}()
+}
@@ -2,13 +2,20 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"unsafe"
 
 	"github.com/gofiber/fiber/v2"
+	pkgerrors "github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/middleware"
 	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
 	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/report"
 )
 
 type httpError struct {
@@ -23,7 +30,17 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 	code := fiber.StatusInternalServerError
 	reqID, _ := c.Context().UserValue(fiber.HeaderXRequestID).(string)
 
+	// never expose the panic value to the client
+	var pe *middleware.PanicError
+	if errors.As(err, &pe) {
+		err = rerr.InternalServerError.With(pe).WithLogMsg("recovered from panic")
+	}
+
 	errCode := -1
+	errMsg := err.Error()
+
+	// the message is translated, the error code stays the same for all languages
+	lang := rerr.Negotiate(c.Get(fiber.HeaderAcceptLanguage))
 
 	l := log.With(zap.String("request_id", reqID))
 
@@ -37,6 +54,7 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 
 		code = e.HTTPCode()
 		errCode = int(e.Code)
+		errMsg = e.Localize(lang)
 		logMsg := e.LogMsg()
 
 		if logMsg != "" {
@@ -50,13 +68,71 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 		}
 	}
 
+	recordSpanError(ftracer.SpanFromCtx(c), err, errCode)
+
+	if code >= fiber.StatusInternalServerError {
+		reportServerError(c, err, reqID, pe)
+	}
+
+	c.Set(fiber.HeaderContentLanguage, string(lang))
+
 	return c.Status(code).JSON(&httpError{
 		ErrorCode:  errCode,
 		Statuscode: code,
-		Error:      err.Error(),
+		Error:      errMsg,
 	})
 }
 
+// reportServerError reports the error to the configured error reporter.
+func reportServerError(c *fiber.Ctx, err error, reqID string, pe *middleware.PanicError) {
+	var stack string
+	if pe != nil {
+		stack = pe.Stack
+	} else if st, ok := findStackTracer(err); ok {
+		stack = fmt.Sprintf("%+v", st.StackTrace())
+	}
+
+	report.Report(ftracer.FromCtx(c), report.Event{
+		Err:       err,
+		RequestID: reqID,
+		Method:    c.Method(),
+		Route:     c.Route().Path,
+		UserID:    middleware.UserIDFromCtx(c),
+		Stack:     stack,
+	})
+}
+
+// stackTracer is implemented by all errors of github.com/pkg/errors.
+type stackTracer interface {
+	StackTrace() pkgerrors.StackTrace
+}
+
+// findStackTracer returns the innermost error with a stack trace.
+func findStackTracer(err error) (st stackTracer, found bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if s, ok := err.(stackTracer); ok {
+			st, found = s, true
+		}
+	}
+
+	return st, found
+}
+
+// recordSpanError records the error returned from a handler on the request span.
+func recordSpanError(span trace.Span, err error, errCode int) {
+	attrs := []attribute.KeyValue{
+		attribute.Int("rerr.code", errCode),
+	}
+
+	var e rerr.Error
+	if errors.As(err, &e) {
+		attrs = append(attrs, attribute.Int("rerr.type", int(e.ErrType)))
+	}
+
+	span.SetAttributes(attrs...)
+	span.RecordError(err, trace.WithAttributes(attrs...))
+}
+
 // byteSlice2String converts a byte slice to a string in a performant way.
 func byteSlice2String(bs []byte) string {
 	return *(*string)(unsafe.Pointer(&bs))
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+)
+
+func TestErrorHandlerLanguage(t *testing.T) {
+	log.InitLogging("fatal", "development")
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/", func(c *fiber.Ctx) error { return rerr.NotFound })
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		lang           string
+		msg            string
+	}{
+		{name: "no header", lang: "en", msg: rerr.NotFound.Message},
+		{name: "unsupported language", acceptLanguage: "fr-FR, fr;q=0.9", lang: "en", msg: rerr.NotFound.Message},
+		{name: "invalid header", acceptLanguage: "de;q=x", lang: "en", msg: rerr.NotFound.Message},
+		{name: "region", acceptLanguage: "de-DE", lang: "de", msg: "nicht gefunden"},
+		{name: "first supported", acceptLanguage: "fr, de, en", lang: "de", msg: "nicht gefunden"},
+		{name: "quality", acceptLanguage: "de;q=0.1, en", lang: "en", msg: rerr.NotFound.Message},
+		{name: "quality ascending", acceptLanguage: "en;q=0.5, fr;q=0.8, de;q=0.7", lang: "de", msg: "nicht gefunden"},
+		{name: "rejected", acceptLanguage: "de;q=0, *", lang: "en", msg: rerr.NotFound.Message},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set(fiber.HeaderAcceptLanguage, tt.acceptLanguage)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if got := resp.Header.Get(fiber.HeaderContentLanguage); got != tt.lang {
+				t.Errorf("expected Content-Language %q, got %q", tt.lang, got)
+			}
+
+			var body httpError
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			// the error code never depends on the language
+			want := rerr.NotFound.Localize(rerr.Language(tt.lang))
+			if body.Error != want || body.ErrorCode != int(rerr.NotFound.Code) || body.Statuscode != fiber.StatusNotFound {
+				t.Errorf("expected error %q with code %d, got %+v", want, rerr.NotFound.Code, body)
+			}
+
+			if got := rerr.NotFound.LocalizedMessage(rerr.Language(tt.lang)); got != tt.msg {
+				t.Errorf("expected message %q, got %q", tt.msg, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package ftracer
+
+import (
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// headerCarrier adapts the fasthttp request header to a [propagation.TextMapCarrier].
+type headerCarrier struct {
+	h *fasthttp.RequestHeader
+}
+
+var _ propagation.TextMapCarrier = headerCarrier{}
+
+// Get returns the value associated with the passed key.
+func (c headerCarrier) Get(key string) string {
+	return string(c.h.Peek(key))
+}
+
+// Set stores the key-value pair.
+func (c headerCarrier) Set(key, value string) {
+	c.h.Set(key, value)
+}
+
+// Keys lists the keys stored in this carrier.
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, c.h.Len())
+
+	c.h.VisitAll(func(k, _ []byte) {
+		keys = append(keys, string(k))
+	})
+
+	return keys
+}
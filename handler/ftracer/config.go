@@ -1,6 +1,7 @@
 package ftracer
 
 import (
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -10,15 +11,21 @@ type Config struct {
 	TracerStartAttributes []trace.SpanStartOption
 	SpanName              string
 	LocalKeyName          string
+	// Propagator extracts the trace context of the caller from the request headers.
+	// The global propagator is used if it is nil.
+	Propagator propagation.TextMapPropagator
+	// TraceIDHeader is the response header containing the trace ID.
+	// The trace ID is not added to the response if it is set to "-".
+	TraceIDHeader string
 }
 
 // ConfigDefault is the default config
 var ConfigDefault = Config{
-	SpanName:     "http/request",
-	LocalKeyName: LocalsCtxKey,
+	SpanName:      "http/request",
+	LocalKeyName:  LocalsCtxKey,
+	TraceIDHeader: "X-Trace-ID",
 	TracerStartAttributes: []trace.SpanStartOption{
 		trace.WithSpanKind(trace.SpanKindServer),
-		trace.WithNewRoot(),
 	},
 }
 
@@ -44,5 +51,9 @@ func configDefault(config ...Config) Config {
 		cfg.TracerStartAttributes = ConfigDefault.TracerStartAttributes
 	}
 
+	if cfg.TraceIDHeader == "" {
+		cfg.TraceIDHeader = ConfigDefault.TraceIDHeader
+	}
+
 	return cfg
 }
@@ -7,16 +7,24 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+// FromCtx returns the context of the request span.
+// If the tracing middleware is not registered, the request context is returned.
 func FromCtx(ctx *fiber.Ctx) context.Context {
-	otelCtx := ctx.Locals(LocalsCtxKey).(context.Context)
+	otelCtx, ok := ctx.Locals(LocalsCtxKey).(context.Context)
+	if !ok {
+		return ctx.Context()
+	}
+
 	return otelCtx
 }
 
+// SpanFromCtx returns the request span.
 func SpanFromCtx(ctx *fiber.Ctx) trace.Span {
-	otelCtx := FromCtx(ctx)
-	if otelCtx == nil {
-		return trace.SpanFromContext(ctx.Context())
-	}
+	return trace.SpanFromContext(FromCtx(ctx))
+}
 
-	return trace.SpanFromContext(otelCtx)
+// SetCtx replaces the context returned by [FromCtx] for the following handlers.
+// The given context must be derived from the context returned by [FromCtx].
+func SetCtx(ctx *fiber.Ctx, c context.Context) {
+	ctx.Locals(LocalsCtxKey, c)
 }
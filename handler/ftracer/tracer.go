@@ -1,8 +1,11 @@
 package ftracer
 
 import (
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -16,8 +19,21 @@ func New(config ...Config) fiber.Handler {
 	// Set default config
 	cfg := configDefault(config...)
 
+	tracer := cfg.Tracer
+	if tracer == nil {
+		tracer = Tracer
+	}
+
 	// Return new handler
 	return func(c *fiber.Ctx) error {
+		propagator := cfg.Propagator
+		if propagator == nil {
+			propagator = otel.GetTextMapPropagator()
+		}
+
+		// continue the trace of the caller, if any
+		parentCtx := propagator.Extract(c.Context(), headerCarrier{h: &c.Request().Header})
+
 		// concat all span options, dynamic and static
 		spanOptions := concatSpanOptions(
 			[]trace.SpanStartOption{
@@ -30,20 +46,22 @@ func New(config ...Config) fiber.Handler {
 				trace.WithAttributes(semconv.HTTPRequestContentLengthKey.Int(c.Request().Header.ContentLength())),
 				trace.WithAttributes(semconv.HTTPSchemeKey.String(c.Protocol())),
 				trace.WithAttributes(semconv.NetTransportTCP),
+				trace.WithAttributes(forwardedForAttributes(c)...),
 				trace.WithSpanKind(trace.SpanKindServer),
-				// TODO:
-				// - x-forwarded-for
-				// -
 			},
 			cfg.TracerStartAttributes,
 		)
 
-		otelCtx, span := Tracer.Start(
-			c.Context(),
+		otelCtx, span := tracer.Start(
+			parentCtx,
 			c.Route().Path,
 			spanOptions...,
 		)
 
+		if sc := span.SpanContext(); sc.HasTraceID() && cfg.TraceIDHeader != "-" {
+			c.Set(cfg.TraceIDHeader, sc.TraceID().String())
+		}
+
 		c.Locals(LocalsCtxKey, otelCtx)
 		defer span.End()
 
@@ -60,6 +78,23 @@ func New(config ...Config) fiber.Handler {
 	}
 }
 
+// forwardedForAttributes returns the span attributes of the X-Forwarded-For header.
+// The header is not evaluated by the application itself, so it is recorded even if
+// the proxy is not trusted.
+func forwardedForAttributes(c *fiber.Ctx) []attribute.KeyValue {
+	xff := c.Get(fiber.HeaderXForwardedFor)
+	if xff == "" {
+		return nil
+	}
+
+	clientIP, _, _ := strings.Cut(xff, ",")
+
+	return []attribute.KeyValue{
+		semconv.HTTPClientIPKey.String(strings.TrimSpace(clientIP)),
+		attribute.String("http.request.header.x_forwarded_for", xff),
+	}
+}
+
 func concatSpanOptions(sources ...[]trace.SpanStartOption) []trace.SpanStartOption {
 	var spanOptions []trace.SpanStartOption
 	for _, source := range sources {
@@ -1,13 +1,21 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/pkg/errors"
 	"github.com/rueian/rueidis"
+	"github.com/rueian/rueidis/rueidishook"
+	"github.com/uptrace/bun/migrate"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -15,34 +23,160 @@ import (
 	"github.com/fabmation-gmbh/briefkasten-go/handler/apiv1"
 	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
 	"github.com/fabmation-gmbh/briefkasten-go/handler/middleware"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
 	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
 	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/metrics"
 	"github.com/fabmation-gmbh/briefkasten-go/internal/oauth"
 	"github.com/fabmation-gmbh/briefkasten-go/internal/redis"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/report"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store/bunstore"
+	"github.com/fabmation-gmbh/briefkasten-go/migrations"
 	"github.com/fabmation-gmbh/briefkasten-go/models"
 )
 
 var (
-	app    *fiber.App
 	tracer trace.Tracer
 	rdb    rueidis.Client
+
+	// serverMu guards the state shared by StartServer and Shutdown,
+	// which run in different goroutines.
+	serverMu sync.Mutex
+	app      *fiber.App
+	listener net.Listener
 )
 
 // StartServer will create a new mux router and listen on the configured port.
-func StartServer() error {
+//
+// The startup is aborted if ctx is canceled or Shutdown is called before
+// the server listens, nil is returned in that case.
+func StartServer(ctx context.Context) error {
 	tracer = otel.Tracer("server")
 	log.Info("Starting server...")
 
+	s, blobs, err := setupServer(ctx)
+	if err != nil {
+		if ctx.Err() != nil || shuttingDown.Load() {
+			log.Info("Startup aborted by shutdown", zap.Error(err))
+			return nil
+		}
+
+		return err
+	}
+
+	return serve(s, blobs)
+}
+
+// setupServer connects to all dependencies and returns the stores of the server.
+func setupServer(ctx context.Context) (store.Store, blob.Store, error) {
 	// establish database connection
 	log.Info("Connect to database")
-	models.Connect()
+	if err := models.Connect(); err != nil {
+		return store.Store{}, nil, err
+	}
+
+	if err := models.WaitReady(ctx, config.C.Load().DB.StartupTimeout); err != nil {
+		return store.Store{}, nil, err
+	}
+
+	ms, err := migrations.For(models.Dialect())
+	if err != nil {
+		return store.Store{}, nil, err
+	}
+
+	migrator = migrate.NewMigrator(models.GetDB(), ms)
+
+	// a migration is not aborted by a shutdown, since it would leave the schema half-migrated
+	if err := ctx.Err(); err != nil {
+		return store.Store{}, nil, err
+	}
+
+	if err := ensureMigrations(); err != nil {
+		return store.Store{}, nil, err
+	}
 
 	log.Debug("Initialize OAuth2 Client")
 	oauth.Init()
 	if err := Connect(); err != nil {
-		log.Fatal("Unable to connect to redis", zap.Error(err))
+		return store.Store{}, nil, errors.Wrap(err, "unable to connect to redis")
+	}
+
+	if err := report.Init(); err != nil {
+		return store.Store{}, nil, err
+	}
+
+	blobs, err := blob.New(ctx, config.C.Load())
+	if err != nil {
+		return store.Store{}, nil, err
+	}
+
+	return bunstore.New(models.GetDB()), blobs, nil
+}
+
+// serve starts the background tasks and serves the public API
+// until the server is shut down.
+func serve(s store.Store, blobs blob.Store) error {
+	a := NewApp(s, blobs)
+
+	ln, err := net.Listen(a.Config().Network, config.C.Load().General.Listen)
+	if err != nil {
+		return errors.Wrap(err, "unable to listen")
+	}
+
+	serverMu.Lock()
+
+	if shuttingDown.Load() {
+		serverMu.Unlock()
+		ln.Close()
+
+		log.Info("Startup aborted by shutdown")
+
+		return nil
 	}
 
+	app, listener = a, &closeOnceListener{Listener: ln}
+
+	startInternalServer()
+	startStatsCollector()
+	startBlobMaintenance(s, blobs)
+
+	serverMu.Unlock()
+
+	log.Info("Started successfully!")
+
+	// the listener is closed by Shutdown, even if the app has not started serving yet
+	err = a.Listener(listener)
+	if shuttingDown.Load() {
+		return nil
+	}
+
+	return err
+}
+
+// ensureMigrations ensures that all migrations are applied.
+// They are applied if db.auto_migrate is enabled, otherwise an error is returned.
+func ensureMigrations() error {
+	cfg := config.C.Load()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DB.StartupTimeout)
+	defer cancel()
+
+	group, err := migrations.EnsureApplied(ctx, migrator, cfg.DB.AutoMigrate)
+	if err != nil {
+		return err
+	}
+
+	if group != nil && !group.IsZero() {
+		log.Info("Database migrated", zap.String("group", group.String()))
+	}
+
+	return nil
+}
+
+// NewApp returns the fiber app serving the public API using the given stores.
+func NewApp(s store.Store, blobs blob.Store) *fiber.App {
 	log.Debug("Initializing router")
 
 	decoder := json.Unmarshal
@@ -63,24 +197,76 @@ func StartServer() error {
 		JSONDecoder:             decoder,
 	}
 
-	app = fiber.New(appSettings)
+	app := fiber.New(appSettings)
 
 	registerMiddlewares(app)
 
 	// add all sub-handlers
 	log.Debug("Add all sub-handlers for path prefixes")
 
+	// ========== Health ==========
+	app.Get("/livez", Livez)
+	app.Get("/readyz", Readyz)
+	middleware.RegisterAnonymousRoute("/livez")
+	middleware.RegisterAnonymousRoute("/readyz")
+
 	// ========== API ==========
-	apiv1.AddApiV1(app.Group("/api/v1"))
-	// app.Get("/health", apiv1.HealthHandler(rdb))
-	// middleware.RegisterAnonymousRoute("/health")
+	apiv1.AddApiV1(app.Group("/api/v1"), apiv1.NewServer(s, blobs))
 
-	log.Info("Started successfully!")
+	return app
+}
+
+// Shutdown gracefully shuts down the server.
+//
+// The readiness endpoint immediately reports the shutdown, but the server
+// keeps handling requests for the configured shutdown delay, so that a
+// load balancer is able to drain the node.
+// Afterwards all open connections are closed within the given timeout.
+func Shutdown(timeout time.Duration) error {
+	serverMu.Lock()
+	shuttingDown.Store(true)
+	a, ln := app, listener
+	serverMu.Unlock()
+
+	stopBlobMaintenance()
 
-	return app.Listen(config.C.General.Listen)
+	// the startup has not finished, StartServer returns without listening
+	if a == nil {
+		return nil
+	}
+
+	if delay := config.C.Load().General.ShutdownDelay; delay > 0 {
+		log.Info("Draining server before shutdown", zap.Duration("delay", delay))
+		time.Sleep(delay)
+	}
+
+	log.Info("Shutting down server...")
+
+	// report all pending errors
+	defer report.Flush(timeout)
+
+	return untilError(
+		ln.Close,
+		func() error { return a.ShutdownWithTimeout(timeout) },
+		func() error { return shutdownInternalServer(timeout) },
+	)
+}
+
+// closeOnceListener is a listener, which can be closed multiple times.
+// Closing it before the app serves makes the app return immediately.
+type closeOnceListener struct {
+	net.Listener
+
+	once sync.Once
+	err  error
 }
 
-const defaultFormat = "${time} [${request_id}] ${method} ${path} - ${ip} (${forwarded_for}) - ${status} - ${latency}\n"
+// Close implements [net.Listener].
+func (l *closeOnceListener) Close() error {
+	l.once.Do(func() { l.err = l.Listener.Close() })
+
+	return l.err
+}
 
 // registerMiddlewares registers all middlewares.
 func registerMiddlewares(app *fiber.App) {
@@ -91,21 +277,76 @@ func registerMiddlewares(app *fiber.App) {
 	// the Request ID middleware must be executed before all the other middlewares.
 	app.Use(middleware.NewRequestID)
 
-	app.Use(logger.New(logger.Config{
-		Format:     defaultFormat,
-		TimeFormat: "02-Jan-2006",
-		TimeZone:   "Europe/Berlin",
-	}))
+	cfg := config.C.Load()
+
+	if cfg.Metrics.Enabled {
+		app.Use(middleware.NewMetrics)
+	}
 
-	if config.C.General.EnableCompression {
-		app.Use(compress.New(compress.Config{
-			Level: compress.Level(config.C.General.CompressionLevel),
+	if cfg.Log.Access.Enabled {
+		app.Use(middleware.NewAccessLog(middleware.AccessLogConfig{
+			SkipAnonymous: cfg.Log.Access.SkipAnonymous,
+			SampleRates:   cfg.Log.Access.SampleRates,
 		}))
 	}
 
-	app.Use(cors.New(cors.Config{
-		AllowCredentials: true,
-	}))
+	// the Recover middleware must be executed after all middlewares calling the
+	// error handler, otherwise the panic is not handled by them.
+	app.Use(middleware.NewRecover)
+
+	// the following middlewares can be changed without a restart
+
+	app.Use(middleware.NewDynamic(
+		func(cfg *config.Config) []string { return cfg.General.CORS.AllowOrigins },
+		func(origins []string) fiber.Handler {
+			return cors.New(cors.Config{
+				AllowOrigins:     strings.Join(origins, ","),
+				AllowCredentials: true,
+			})
+		},
+	))
+
+	app.Use(middleware.NewDynamic(
+		func(cfg *config.Config) config.RateLimit { return cfg.General.RateLimit },
+		func(rl config.RateLimit) fiber.Handler {
+			if !rl.Enabled {
+				return skipMiddleware
+			}
+
+			return limiter.New(limiter.Config{
+				Next:       func(c *fiber.Ctx) bool { return middleware.IsAnonymousRoute(c.Path()) },
+				Max:        rl.Max,
+				Expiration: rl.Expiration,
+				LimitReached: func(c *fiber.Ctx) error {
+					return rerr.RateLimited
+				},
+			})
+		},
+	))
+
+	app.Use(middleware.NewDynamic(
+		func(cfg *config.Config) [2]uint {
+			if !cfg.General.EnableCompression {
+				return [2]uint{0, 0}
+			}
+
+			return [2]uint{1, cfg.General.CompressionLevel}
+		},
+		func(v [2]uint) fiber.Handler {
+			if v[0] == 0 {
+				return skipMiddleware
+			}
+
+			return compress.New(compress.Config{
+				Level: compress.Level(v[1]),
+			})
+		},
+	))
+}
+
+// skipMiddleware is a middleware doing nothing.
+func skipMiddleware(c *fiber.Ctx) error {
+	return c.Next()
 }
 
 func untilError(f ...func() error) error {
@@ -118,13 +359,41 @@ func untilError(f ...func() error) error {
 	return nil
 }
 
+// newRedisClientOption returns the options of the redis client.
+// Tests replace it, since miniredis does not support client-side caching.
+var newRedisClientOption = redis.NewClientOption
+
 // Connect will connect to the Redis DB
 func Connect() (err error) {
-	rdb, err = rueidis.NewClient(rueidis.ClientOption{
-		InitAddress: config.C.Redis.Address,
-	})
+	cfg := config.C.Load()
 
-	redis.Connect(rdb)
+	opt, err := newRedisClientOption(cfg)
+	if err != nil {
+		return err
+	}
 
-	return err
+	rdb, err = rueidis.NewClient(opt)
+	if err != nil {
+		return errors.Wrapf(err, "unable to connect to redis (mode %s, addresses %v)", cfg.Redis.Mode, cfg.Redis.Address)
+	}
+
+	// NewClient only establishes the connection, the PING verifies
+	// that the authentication and database selection succeeded.
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Redis.DialTimeout)
+	defer cancel()
+
+	if err := rdb.Do(ctx, rdb.B().Ping().Build()).Error(); err != nil {
+		rdb.Close()
+		return errors.Wrapf(err, "unable to ping redis (mode %s, addresses %v)", cfg.Redis.Mode, cfg.Redis.Address)
+	}
+
+	if cfg.Debug.EnableTracing && cfg.Debug.EnableRedisTracing {
+		rdb = rueidishook.WithHook(rdb, redis.TracingHook{})
+	}
+
+	if cfg.Metrics.Enabled {
+		rdb = rueidishook.WithHook(rdb, metrics.RedisHook{})
+	}
+
+	return redis.Connect(rdb)
 }
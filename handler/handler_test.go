@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/rueian/rueidis"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/redis"
+)
+
+func TestShutdownDuringStartup(t *testing.T) {
+	newRedisClientOption = func(cfg *config.Config) (rueidis.ClientOption, error) {
+		opt, err := redis.NewClientOption(cfg)
+		opt.DisableCache = true
+
+		return opt, err
+	}
+	defer func() { newRedisClientOption = redis.NewClientOption }()
+
+	t.Run("waiting for the database", func(t *testing.T) {
+		loadTestConfig(t, "postgres://briefkasten@127.0.0.1:1/briefkasten?sslmode=disable")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errCh := startServer(ctx)
+
+		// the database is never reachable, the startup retries until the startup timeout
+		time.Sleep(300 * time.Millisecond)
+
+		cancel()
+		shutdown(t, errCh)
+	})
+
+	// the shutdown may happen at any point of the startup, including
+	// the moment between creating the listener and serving
+	dbURI := "sqlite:" + filepath.Join(t.TempDir(), "briefkasten.db")
+
+	for _, delay := range []time.Duration{0, time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond, 200 * time.Millisecond} {
+		t.Run("after "+delay.String(), func(t *testing.T) {
+			loadTestConfig(t, dbURI)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			errCh := startServer(ctx)
+			time.Sleep(delay)
+
+			cancel()
+			shutdown(t, errCh)
+		})
+	}
+}
+
+func TestStartServerRedisUnavailable(t *testing.T) {
+	loadTestConfig(t, "sqlite:"+filepath.Join(t.TempDir(), "briefkasten.db"))
+
+	cfg := *config.C.Load()
+	cfg.Redis.Address = []string{freeAddr(t)}
+	config.C.Store(&cfg)
+
+	err := StartServer(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "unable to connect to redis") {
+		t.Fatalf("expected a redis connection error, got %v", err)
+	}
+}
+
+// startServer starts the server in the background and returns the result of StartServer.
+func startServer(ctx context.Context) <-chan error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- StartServer(ctx) }()
+
+	return errCh
+}
+
+// shutdown shuts the server down and expects StartServer to return without an error.
+func shutdown(t *testing.T, errCh <-chan error) {
+	t.Helper()
+
+	if err := Shutdown(time.Second); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("expected no error after the shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server has not stopped after the shutdown")
+	}
+}
+
+// loadTestConfig loads a configuration using the database and a new redis server
+// and resets the server state of a previous test.
+func loadTestConfig(t *testing.T, dbURI string) {
+	t.Helper()
+
+	serverMu.Lock()
+	app, listener = nil, nil
+	shuttingDown.Store(false)
+	serverMu.Unlock()
+
+	// a failed connection of a previous test leaves an unusable client
+	rdb = nil
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(path, []byte(`
+general:
+  environment: "development"
+  shutdown_delay: "0s"
+  jwt:
+    signing_key: "handler-signing-key-handler-signing-key"
+
+log:
+  level: "fatal"
+
+db:
+  startup_timeout: "1m"
+  auto_migrate: true
+
+internal:
+  listen: ""
+
+metrics:
+  enabled: false
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := config.LoadConfig(path, map[string]any{
+		"general.listen": freeAddr(t),
+		"db.uri":         dbURI,
+		"redis.address":  []string{miniredis.RunT(t).Addr()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.C.Load()
+	log.InitLogging(cfg.Log.Level, cfg.General.Environment)
+}
+
+// freeAddr returns a free address on the loopback interface.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	return ln.Addr().String()
+}
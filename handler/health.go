@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun/migrate"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// healthCheckTimeout is the maximum duration of a single dependency check.
+const healthCheckTimeout = 2 * time.Second
+
+const (
+	healthStatusOK   = "ok"
+	healthStatusFail = "fail"
+)
+
+var (
+	// shuttingDown is set as soon as the graceful shutdown has been started.
+	shuttingDown atomic.Bool
+	// migrationsApplied caches a successful migration check.
+	// Migrations can not become unapplied while the server is running.
+	migrationsApplied atomic.Bool
+	migrator          *migrate.Migrator
+)
+
+// healthCheck is the result of a single dependency check.
+type healthCheck struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// healthResponse is the response of the health endpoints.
+type healthResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]healthCheck `json:"checks"`
+}
+
+// healthCheckFunc checks a single dependency.
+type healthCheckFunc func(ctx context.Context) error
+
+// Livez is the liveness endpoint.
+//
+// It reports the state of all dependencies, but only fails if the
+// server is not able to handle requests at all, so that a
+// dependency outage does not result in restarting all instances.
+func Livez(c *fiber.Ctx) error {
+	resp := runHealthChecks(ftracer.FromCtx(c), dependencyChecks())
+	resp.Status = healthStatusOK
+
+	return c.JSON(resp)
+}
+
+// Readyz is the readiness endpoint.
+//
+// It fails if any dependency is unreachable, if migrations are pending
+// or if the server is shutting down, so that a load balancer can drain the node.
+func Readyz(c *fiber.Ctx) error {
+	checks := dependencyChecks()
+	checks["migrations"] = checkMigrations
+	checks["shutdown"] = checkShutdown
+
+	resp := runHealthChecks(ftracer.FromCtx(c), checks)
+	if resp.Status != healthStatusOK {
+		c.Status(fiber.StatusServiceUnavailable)
+	}
+
+	return c.JSON(resp)
+}
+
+// dependencyChecks returns the checks of all external dependencies.
+func dependencyChecks() map[string]healthCheckFunc {
+	return map[string]healthCheckFunc{
+		"database": checkDatabase,
+		"redis":    checkRedis,
+	}
+}
+
+// runHealthChecks executes all checks concurrently.
+func runHealthChecks(ctx context.Context, checks map[string]healthCheckFunc) healthResponse {
+	resp := healthResponse{
+		Status: healthStatusOK,
+		Checks: make(map[string]healthCheck, len(checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for name, check := range checks {
+		wg.Add(1)
+
+		go func(name string, check healthCheckFunc) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(ctx)
+			res := healthCheck{
+				Status:  healthStatusOK,
+				Latency: time.Since(start).String(),
+			}
+
+			if err != nil {
+				res.Status = healthStatusFail
+				res.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			resp.Checks[name] = res
+			if err != nil {
+				resp.Status = healthStatusFail
+			}
+		}(name, check)
+	}
+
+	wg.Wait()
+
+	return resp
+}
+
+func checkDatabase(ctx context.Context) error {
+	return errors.Wrap(models.GetDB().PingContext(ctx), "unable to ping database")
+}
+
+func checkRedis(ctx context.Context) error {
+	if rdb == nil {
+		return errors.New("redis client not initialized")
+	}
+
+	err := rdb.Do(ctx, rdb.B().Ping().Build()).Error()
+
+	return errors.Wrap(err, "unable to ping redis")
+}
+
+func checkMigrations(ctx context.Context) error {
+	if migrationsApplied.Load() {
+		return nil
+	}
+
+	ms, err := migrator.MigrationsWithStatus(ctx)
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve migration status")
+	}
+
+	if unapplied := ms.Unapplied(); len(unapplied) > 0 {
+		return errors.Errorf("%d unapplied migrations", len(unapplied))
+	}
+
+	migrationsApplied.Store(true)
+
+	return nil
+}
+
+func checkShutdown(_ context.Context) error {
+	if shuttingDown.Load() {
+		return errors.New("server is shutting down")
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+func TestLivezDatabase(t *testing.T) {
+	// the check is named independent of the database dialect
+	loadTestConfig(t, "sqlite:"+filepath.Join(t.TempDir(), "briefkasten.db"))
+
+	if err := models.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Get("/livez", Livez)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/livez", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body healthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+
+	if check, ok := body.Checks["database"]; !ok || check.Status != healthStatusOK {
+		t.Errorf("expected a successful database check, got %+v", body.Checks)
+	}
+
+	if _, ok := body.Checks["postgres"]; ok {
+		t.Errorf("expected no postgres check for SQLite, got %+v", body.Checks)
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+	"go.uber.org/zap"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/middleware"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/metrics"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+var (
+	internalApp *fiber.App
+	// stopStats stops the business statistics collector.
+	stopStats context.CancelFunc = func() {}
+)
+
+// startInternalServer starts the internal listener in the background.
+// The internal listener serves all non-public endpoints and must
+// therefore never be exposed.
+func startInternalServer() {
+	cfg := config.C.Load()
+	addr := cfg.Internal.Listen
+	if addr == "" {
+		log.Debug("Internal listener is disabled")
+		return
+	}
+
+	internalApp = fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+		ErrorHandler:          ErrorHandler,
+	})
+
+	internalApp.Use(middleware.NewRequestID)
+
+	addAdminRoutes(internalApp)
+
+	if cfg.Metrics.Enabled {
+		h := fasthttpadaptor.NewFastHTTPHandler(metrics.Handler())
+		internalApp.Get("/metrics", func(c *fiber.Ctx) error {
+			h(c.Context())
+			return nil
+		})
+	}
+
+	go func() {
+		log.Info("Starting internal listener", zap.String("listen", addr))
+
+		if err := internalApp.Listen(addr); err != nil {
+			log.Error("Internal listener stopped unexpectedly", zap.Error(err))
+		}
+	}()
+}
+
+// shutdownInternalServer shuts down the internal listener.
+func shutdownInternalServer(timeout time.Duration) error {
+	stopStats()
+
+	if internalApp == nil {
+		return nil
+	}
+
+	return internalApp.ShutdownWithTimeout(timeout)
+}
+
+// startStatsCollector periodically refreshes the business statistics.
+func startStatsCollector() {
+	cfg := config.C.Load()
+	if !cfg.Metrics.Enabled {
+		return
+	}
+
+	var ctx context.Context
+	ctx, stopStats = context.WithCancel(context.Background())
+
+	go func() {
+		ticker := time.NewTicker(cfg.Metrics.StatsInterval)
+		defer ticker.Stop()
+
+		for {
+			stats, err := models.GetStats(ctx)
+			if err != nil {
+				log.Warn("Unable to refresh statistics", zap.Error(err))
+			} else {
+				metrics.SetStats(metrics.Stats(stats))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
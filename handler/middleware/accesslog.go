@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+)
+
+// AccessLogConfig defines the config of the 'Access Log' middleware.
+type AccessLogConfig struct {
+	// SkipAnonymous skips all routes registered with [RegisterAnonymousRoute],
+	// i.e. health checks.
+	SkipAnonymous bool
+	// SampleRates holds the ratio (between 0 and 1) of logged requests per route.
+	// Routes without a sample rate are always logged.
+	// Failed requests (5xx) are always logged.
+	SampleRates map[string]float64
+}
+
+// NewAccessLog returns the 'Access Log' middleware.
+// It logs every request using zap.
+//
+// The middleware calls the error handler itself, so that the final status
+// code is known. Errors are therefore not returned to previous middlewares.
+func NewAccessLog(cfg AccessLogConfig) fiber.Handler {
+	l := log.NewAccessLogger()
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		if chainErr := c.Next(); chainErr != nil {
+			if err := c.App().ErrorHandler(c, chainErr); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := c.Route().Path
+		status := c.Response().StatusCode()
+
+		if cfg.SkipAnonymous && IsAnonymousRoute(route) {
+			return nil
+		}
+
+		if rate, ok := cfg.SampleRates[route]; ok && status < fiber.StatusInternalServerError && rand.Float64() >= rate {
+			return nil
+		}
+
+		reqID, _ := c.Context().UserValue(fiber.HeaderXRequestID).(string)
+
+		fields := []zap.Field{
+			zap.String("request_id", reqID),
+			zap.String("method", c.Method()),
+			zap.String("route", route),
+			zap.String("path", c.Path()),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.Int("bytes", len(c.Response().Body())),
+			zap.String("ip", c.IP()),
+		}
+
+		if sc := ftracer.SpanFromCtx(c).SpanContext(); sc.HasTraceID() {
+			fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+		}
+
+		if userID := UserIDFromCtx(c); userID != "" {
+			fields = append(fields, zap.String("user_id", userID))
+		}
+
+		l.Info("Request handled", fields...)
+
+		return nil
+	}
+}
+
+// UserIDFromCtx returns the user ID of the authenticated user, if any.
+func UserIDFromCtx(c *fiber.Ctx) string {
+	userID, _ := claims(c)["user_id"].(string)
+
+	return userID
+}
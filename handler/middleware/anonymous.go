@@ -0,0 +1,30 @@
+package middleware
+
+import "sync"
+
+var (
+	anonymousRoutesMu sync.RWMutex
+	anonymousRoutes   = make(map[string]struct{})
+)
+
+// RegisterAnonymousRoute registers the given route path as anonymous.
+// Anonymous routes are infrastructure routes (i.e. health checks),
+// which can be accessed without authentication and may be skipped by
+// middlewares like the request logger.
+func RegisterAnonymousRoute(path string) {
+	anonymousRoutesMu.Lock()
+	defer anonymousRoutesMu.Unlock()
+
+	anonymousRoutes[path] = struct{}{}
+}
+
+// IsAnonymousRoute returns true if the route path has been registered
+// with [RegisterAnonymousRoute].
+func IsAnonymousRoute(path string) bool {
+	anonymousRoutesMu.RLock()
+	defer anonymousRoutesMu.RUnlock()
+
+	_, ok := anonymousRoutes[path]
+
+	return ok
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+// NewDynamic returns a middleware which is rebuilt whenever the selected
+// part of the configuration changes, i.e. after a configuration reload.
+//
+// The select function must return a copy of the configuration values
+// required to build the middleware. If the middleware is rebuilt,
+// its state (i.e. rate limit counters) is lost.
+func NewDynamic[T any](sel func(cfg *config.Config) T, build func(v T) fiber.Handler) fiber.Handler {
+	var (
+		mu      sync.RWMutex
+		current T
+		handler fiber.Handler
+	)
+
+	rebuild := func(v T) fiber.Handler {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// another request may have rebuilt the handler in the meantime
+		if handler == nil || !reflect.DeepEqual(current, v) {
+			current = v
+			handler = build(v)
+		}
+
+		return handler
+	}
+
+	return func(c *fiber.Ctx) error {
+		v := sel(config.C.Load())
+
+		mu.RLock()
+		h := handler
+		upToDate := h != nil && reflect.DeepEqual(current, v)
+		mu.RUnlock()
+
+		if !upToDate {
+			h = rebuild(v)
+		}
+
+		return h(c)
+	}
+}
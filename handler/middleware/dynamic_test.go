@@ -0,0 +1,75 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/middleware"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+func TestDynamic(t *testing.T) {
+	defer config.C.Store(config.C.Load())
+
+	var cfg config.Config
+	cfg.Log.Level = "info"
+	cfg.General.CORS.AllowOrigins = []string{"https://a.example.com"}
+	config.C.Store(&cfg)
+
+	var builds int
+
+	app := fiber.New()
+	app.Use(middleware.NewDynamic(
+		func(cfg *config.Config) []string { return cfg.General.CORS.AllowOrigins },
+		func(origins []string) fiber.Handler {
+			builds++
+
+			return func(c *fiber.Ctx) error {
+				return c.SendString(origins[0])
+			}
+		},
+	))
+
+	request := func() string {
+		t.Helper()
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(body)
+	}
+
+	if got := request(); got != "https://a.example.com" || builds != 1 {
+		t.Fatalf("expected the first origin and one build, got %q and %d builds", got, builds)
+	}
+
+	// other configuration changes do not rebuild the middleware
+	next := cfg
+	next.Log.Level = "debug"
+	config.C.Store(&next)
+
+	if got := request(); got != "https://a.example.com" || builds != 1 {
+		t.Fatalf("expected no rebuild, got %q and %d builds", got, builds)
+	}
+
+	next.General.CORS.AllowOrigins = []string{"https://b.example.com"}
+	config.C.Store(&next)
+
+	for i := 0; i < 3; i++ {
+		if got := request(); got != "https://b.example.com" || builds != 2 {
+			t.Fatalf("expected a single rebuild with the new origin, got %q and %d builds", got, builds)
+		}
+	}
+}
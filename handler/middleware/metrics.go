@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/metrics"
+)
+
+// NewMetrics returns the 'Metrics' middleware.
+// It records the number and latency of all requests per route.
+//
+// The middleware calls the error handler itself, so that the final status
+// code is known. Errors are therefore not returned to previous middlewares.
+func NewMetrics(f *fiber.Ctx) error {
+	start := time.Now()
+
+	if chainErr := f.Next(); chainErr != nil {
+		if err := f.App().ErrorHandler(f, chainErr); err != nil {
+			_ = f.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	metrics.ObserveHTTPRequest(
+		f.Method(),
+		f.Route().Path,
+		f.Response().StatusCode(),
+		time.Since(start),
+	)
+
+	return nil
+}
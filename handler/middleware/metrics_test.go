@@ -0,0 +1,55 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/middleware"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/metrics"
+)
+
+func TestMetricsStatus(t *testing.T) {
+	app := fiber.New()
+	app.Use(middleware.NewMetrics)
+	app.Use(middleware.NewRecover)
+
+	app.Get("/metrics-test/ok", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	app.Get("/metrics-test/error", func(c *fiber.Ctx) error { return fiber.ErrTeapot })
+	app.Get("/metrics-test/panic", func(c *fiber.Ctx) error { panic("boom") })
+
+	for path, status := range map[string]int{
+		"/metrics-test/ok":    fiber.StatusOK,
+		"/metrics-test/error": fiber.StatusTeapot,
+		"/metrics-test/panic": fiber.StatusInternalServerError,
+	} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != status {
+			t.Errorf("%s: expected status %d, got %d", path, status, resp.StatusCode)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, _ := io.ReadAll(rec.Body)
+
+	for _, want := range []string{
+		`briefkasten_http_requests_total{method="GET",route="/metrics-test/ok",status="200"} 1`,
+		`briefkasten_http_requests_total{method="GET",route="/metrics-test/error",status="418"} 1`,
+		`briefkasten_http_requests_total{method="GET",route="/metrics-test/panic",status="500"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected the metric %s", want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PanicError is the error returned by the 'Recover' middleware
+// if a handler panicked.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack string
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// NewRecover returns the 'Recover' middleware.
+// It recovers from panics in the following handlers and returns
+// a [PanicError] to the error handler instead.
+func NewRecover(f *fiber.Ctx) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{
+				Value: r,
+				Stack: string(debug.Stack()),
+			}
+		}
+	}()
+
+	return f.Next()
+}
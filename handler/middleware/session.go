@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
+	"github.com/fabmation-gmbh/briefkasten-go/handler/rerr"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/auth"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// localsAccountKey is the key of the authenticated user in the locals.
+const localsAccountKey = "account"
+
+// NewSession returns the 'Session' middleware.
+// It must be registered after the JWT middleware and loads the user of the token.
+//
+// Tokens of deleted users and tokens of an older token generation
+// (see [models.UserAccount.TokenGeneration]) are rejected,
+// disabled users are denied access.
+func NewSession(users store.UserStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := uuid.Parse(UserIDFromCtx(c))
+		if err != nil {
+			return rerr.Unauthenticated.With(err).WithLogMsg("invalid user ID in token")
+		}
+
+		u, err := users.Get(ftracer.FromCtx(c), id)
+		if errors.Is(err, store.ErrNotFound) {
+			return rerr.Unauthenticated.With(err).WithLogMsg("user of token does not exist")
+		} else if err != nil {
+			return rerr.InternalServerError.With(err).WithLogMsg("unable to retrieve user of token")
+		}
+
+		// tokens issued before the generation has been introduced have none
+		gen, _ := claims(c)[auth.ClaimGeneration].(float64)
+		if int64(gen) != u.TokenGeneration {
+			return rerr.Unauthenticated.WithLogMsg("token has been revoked")
+		}
+
+		if u.IsDisabled() {
+			return rerr.PermissionDenied.WithLogMsg("user account is disabled")
+		}
+
+		c.Locals(localsAccountKey, u)
+
+		return c.Next()
+	}
+}
+
+// RequireRole returns a middleware, which only allows users with the given role.
+func RequireRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if r, _ := claims(c)[auth.ClaimRole].(string); r != role {
+			return rerr.PermissionDenied.WithLogMsg("missing role " + role)
+		}
+
+		return c.Next()
+	}
+}
+
+// AccountFromCtx returns the authenticated user loaded by the 'Session' middleware.
+func AccountFromCtx(c *fiber.Ctx) (models.UserAccount, bool) {
+	u, ok := c.Locals(localsAccountKey).(models.UserAccount)
+
+	return u, ok
+}
+
+// claims returns the claims of the token, if any.
+func claims(c *fiber.Ctx) jwt.MapClaims {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return nil
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+
+	return claims
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler/ftracer"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+)
+
+// errRollback is returned from the transaction to roll it back
+// without reporting an error.
+var errRollback = errors.New("rollback transaction")
+
+// NewTransaction returns the 'Transaction' middleware.
+// It runs the following handlers in a transaction of the unit of work,
+// which is carried by the request context (see [ftracer.FromCtx]).
+//
+// The transaction is committed if the handlers return no error and the
+// response status is below 400, otherwise it is rolled back.
+// Requests with a safe method (GET, HEAD, OPTIONS) are not wrapped.
+func NewTransaction(uow store.UnitOfWork) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return c.Next()
+		}
+
+		ctx := ftracer.FromCtx(c)
+		defer ftracer.SetCtx(c, ctx)
+
+		err := uow.RunInTx(ctx, func(txCtx context.Context) error {
+			ftracer.SetCtx(c, txCtx)
+
+			if err := c.Next(); err != nil {
+				return err
+			}
+
+			if c.Response().StatusCode() >= fiber.StatusBadRequest {
+				return errRollback
+			}
+
+			return nil
+		})
+		if errors.Is(err, errRollback) {
+			return nil
+		}
+
+		return err
+	}
+}
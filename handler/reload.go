@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/audit"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+)
+
+// ReloadConfig reloads the configuration from the given path and applies
+// all settings which can be changed without a restart.
+// Changes of all other settings are logged and ignored.
+//
+// The CORS, rate limit and compression middlewares as well as the
+// OAuth provider list pick up the new configuration with the next request.
+// The log level and SQL debug output are only changed if their value in the
+// file changed, so that changes made using the admin API are kept otherwise.
+func ReloadConfig(ctx context.Context, actor, path string, flags map[string]any) error {
+	res, err := config.Reload(path, flags)
+	if err != nil {
+		return err
+	}
+
+	if len(res.Rejected) > 0 {
+		log.Error("Configuration changes require a restart, keeping the old values",
+			zap.Strings("keys", res.Rejected),
+		)
+	}
+
+	if len(res.Applied) == 0 {
+		log.Info("Configuration reloaded, nothing changed")
+		return nil
+	}
+
+	cfg := config.C.Load()
+
+	if contains(res.Applied, "log.level") {
+		if err := SetLogLevel(ctx, actor, cfg.Log.Level); err != nil {
+			return err
+		}
+	}
+
+	if contains(res.Applied, "debug.enable_sql_debug") {
+		SetSQLDebug(ctx, actor, cfg.Debug.EnableSQLDebug)
+	}
+
+	audit.Record(ctx, audit.Entry{
+		Actor:    actor,
+		Action:   "config.reload",
+		NewValue: res.Applied,
+	})
+
+	log.Info("Configuration reloaded", zap.Strings("applied", res.Applied))
+
+	return nil
+}
+
+// contains reports whether the list contains s.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
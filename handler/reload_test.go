@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/uptrace/bun/migrate"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/migrations"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+func TestReloadConfigKeepsRuntimeSettings(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	flags := map[string]any{
+		"general.listen": "127.0.0.1:8080",
+		"db.uri":         "sqlite:" + filepath.Join(t.TempDir(), "briefkasten.db"),
+		"redis.address":  []string{"127.0.0.1:6379"},
+	}
+
+	writeConfig := func(level, origin string) {
+		err := os.WriteFile(path, []byte(`
+general:
+  environment: "development"
+  jwt:
+    signing_key: "handler-signing-key-handler-signing-key"
+  cors:
+    allow_origins: ["`+origin+`"]
+
+log:
+  level: "`+level+`"
+
+db:
+  auto_migrate: true
+
+debug:
+  enable_sql_debug: false
+`), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeConfig("error", "https://a.example.com")
+
+	if err := config.LoadConfig(path, flags); err != nil {
+		t.Fatal(err)
+	}
+
+	log.InitLogging(config.C.Load().Log.Level, config.C.Load().General.Environment)
+
+	if err := models.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	ms, err := migrations.For(models.Dialect())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator = migrate.NewMigrator(models.GetDB(), ms)
+	if err := ensureMigrations(); err != nil {
+		t.Fatal(err)
+	}
+
+	// changed using the admin API
+	if err := SetLogLevel(ctx, "test", "warn"); err != nil {
+		t.Fatal(err)
+	}
+
+	SetSQLDebug(ctx, "test", true)
+	defer models.SetSQLDebug(false)
+
+	// other settings changed in the file
+	writeConfig("error", "https://b.example.com")
+
+	if err := ReloadConfig(ctx, "test", path, flags); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := config.C.Load().General.CORS.AllowOrigins; len(got) != 1 || got[0] != "https://b.example.com" {
+		t.Fatalf("expected the new origin, got %v", got)
+	}
+
+	if got := log.Level(); got != "warn" {
+		t.Errorf("expected the log level of the admin API to be kept, got %s", got)
+	}
+
+	if !models.SQLDebug() {
+		t.Error("expected the SQL debug output of the admin API to be kept")
+	}
+
+	// the log level changed in the file
+	writeConfig("info", "https://b.example.com")
+
+	if err := ReloadConfig(ctx, "test", path, flags); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := log.Level(); got != "info" {
+		t.Errorf("expected the log level of the file, got %s", got)
+	}
+
+	if !models.SQLDebug() {
+		t.Error("expected the unchanged SQL debug output to be kept")
+	}
+}
+
+func TestReloadConfigLogsRejectedKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	flags := map[string]any{
+		"db.uri":        "sqlite:" + filepath.Join(t.TempDir(), "briefkasten.db"),
+		"redis.address": []string{"127.0.0.1:6379"},
+	}
+
+	writeConfig := func(listen string) {
+		err := os.WriteFile(path, []byte(`
+general:
+  listen: "`+listen+`"
+  environment: "production"
+  jwt:
+    signing_key: "handler-signing-key-handler-signing-key"
+`), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeConfig("127.0.0.1:8080")
+
+	if err := config.LoadConfig(path, flags); err != nil {
+		t.Fatal(err)
+	}
+
+	// the logger writes to the standard error of the time it is built
+	logFile, err := os.Create(filepath.Join(t.TempDir(), "log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+
+	stderr := os.Stderr
+	os.Stderr = logFile
+	log.InitLogging("info", "production")
+	os.Stderr = stderr
+
+	defer log.InitLogging("info", "production")
+
+	writeConfig("127.0.0.1:9090")
+
+	if err := ReloadConfig(context.Background(), "test", path, flags); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := config.C.Load().General.Listen; got != "127.0.0.1:8080" {
+		t.Errorf("expected the old listen address, got %s", got)
+	}
+
+	data, err := os.ReadFile(logFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), "Configuration changes require a restart") || !strings.Contains(string(data), `"keys":["general.listen"]`) {
+		t.Errorf("expected the rejected key to be logged, got %s", data)
+	}
+}
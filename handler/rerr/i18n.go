@@ -0,0 +1,101 @@
+package rerr
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+// Language is the primary subtag of a BCP 47 language tag, i.e. "en" or "de".
+type Language string
+
+const (
+	// LangEnglish is the English language.
+	LangEnglish Language = "en"
+	// LangGerman is the German language.
+	LangGerman Language = "de"
+)
+
+// DefaultLanguage is the language used if the client does not accept
+// any of the supported languages.
+// The [Error.Message] of every error is written in this language.
+const DefaultLanguage = LangEnglish
+
+// catalog holds the translated messages of all errors.
+// Errors without a translation for a language fall back to [Error.Message].
+//
+// NOTE: Only the message is translated, the [ErrorCode] must never change.
+var catalog = map[ErrorCode]map[Language]string{
+	ecRequestMalformed: {
+		LangGerman: "ungültiger Request-Body/ Header",
+	},
+	ecInternalServerError: {
+		LangGerman: "interner Serverfehler",
+	},
+	ecUnauthenticated: {
+		LangGerman: "nicht authentifiziert",
+	},
+	ecRateLimited: {
+		LangGerman: "zu viele Anfragen",
+	},
+	ecNotFound: {
+		LangGerman: "nicht gefunden",
+	},
+	ecConflict: {
+		LangGerman: "Ressource existiert bereits",
+	},
+	ecPermissionDenied: {
+		LangGerman: "Zugriff verweigert",
+	},
+}
+
+// Languages returns all supported languages.
+// The first entry is always the [DefaultLanguage].
+func Languages() []string {
+	return []string{
+		string(LangEnglish),
+		string(LangGerman),
+	}
+}
+
+// Negotiate returns the supported language preferred by the client
+// according to the Accept-Language header.
+//
+// The languages are ordered by their quality value, unlike
+// [fiber.Ctx.AcceptsLanguages], which ignores it. If the client
+// accepts none of the supported languages, the [DefaultLanguage] is returned.
+func Negotiate(acceptLanguage string) Language {
+	// the tags are sorted by the quality value, tags with q=0 are dropped
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return DefaultLanguage
+	}
+
+	for _, tag := range tags {
+		base, _ := tag.Base()
+
+		for _, lang := range Languages() {
+			if base.String() == lang {
+				return Language(lang)
+			}
+		}
+	}
+
+	return DefaultLanguage
+}
+
+// LocalizedMessage returns the message of the error in the given language.
+// If no translation exists, the (english) default message is returned.
+func (e Error) LocalizedMessage(lang Language) string {
+	if msg, ok := catalog[e.Code][lang]; ok {
+		return msg
+	}
+
+	return e.Message
+}
+
+// Localize returns the string representation of the error, like
+// [Error.Error], with the message translated into the given language.
+func (e Error) Localize(lang Language) string {
+	return fmt.Sprintf("[%d of %d] %s", e.Code, e.ErrType, e.LocalizedMessage(lang))
+}
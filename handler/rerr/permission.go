@@ -0,0 +1,12 @@
+package rerr
+
+import "net/http"
+
+// PermissionDenied describes that the authenticated user is not allowed
+// to perform the requested action.
+var PermissionDenied = newErr(
+	ecPermissionDenied,
+	EPermission,
+	"permission denied",
+	http.StatusForbidden,
+)
@@ -12,6 +12,14 @@ var RequestMalformed = newErr(
 	http.StatusBadRequest,
 )
 
+// RateLimited describes that the client sent too many requests.
+var RateLimited = newErr(
+	ecRateLimited,
+	ERequest,
+	"too many requests",
+	http.StatusTooManyRequests,
+)
+
 // Unauthenticated describes that the client must authenticate itself
 // before proceeding.
 var Unauthenticated = newErr(
@@ -20,3 +28,20 @@ var Unauthenticated = newErr(
 	"unauthenticated",
 	http.StatusBadRequest,
 )
+
+// NotFound describes that the requested resource does not exist.
+var NotFound = newErr(
+	ecNotFound,
+	ERequest,
+	"not found",
+	http.StatusNotFound,
+)
+
+// Conflict describes that the resource conflicts with an existing
+// one, i.e. a tag with the same name already exists.
+var Conflict = newErr(
+	ecConflict,
+	ERequest,
+	"resource already exists",
+	http.StatusConflict,
+)
@@ -23,6 +23,10 @@ const (
 	ecRequestMalformed    = ErrorCode(100)
 	ecInternalServerError = ErrorCode(101)
 	ecUnauthenticated     = ErrorCode(102)
+	ecRateLimited         = ErrorCode(103)
+	ecNotFound            = ErrorCode(104)
+	ecConflict            = ErrorCode(105)
+	ecPermissionDenied    = ErrorCode(106)
 )
 
 // Error is an rerr (request/ REST API) error.
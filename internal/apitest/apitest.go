@@ -0,0 +1,372 @@
+// Package apitest provides a harness to test the API handlers in memory.
+//
+// Every harness boots the fiber app with [fiber.App.Test] against a fresh,
+// migrated database and an in-memory Redis server. The database is a
+// temporary SQLite database, or a throwaway schema of the Postgres
+// database given by the BRIEFKASTEN_TEST_DB_URI environment variable.
+//
+// The configuration, database and Redis connection are global, tests
+// using a harness must therefore not run in parallel.
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rueian/rueidis"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dbfixture"
+	"github.com/uptrace/bun/driver/pgdriver"
+	"github.com/uptrace/bun/migrate"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/auth"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/redis"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store/bunstore"
+	"github.com/fabmation-gmbh/briefkasten-go/migrations"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// DBURIEnv is the environment variable of the Postgres database used by the tests.
+// A temporary SQLite database is used if it is empty.
+const DBURIEnv = "BRIEFKASTEN_TEST_DB_URI"
+
+// SigningKey is the JWT signing key of the test configuration.
+const SigningKey = "apitest-signing-key-apitest-signing-key"
+
+//go:embed fixtures/*.yml
+var fixtureFS embed.FS
+
+// Harness is a running test instance of the API.
+type Harness struct {
+	// App is the fiber app serving the API.
+	App *fiber.App
+	// Store is the store used by the app.
+	Store store.Store
+	// DB is the database connection.
+	DB *bun.DB
+	// Blobs is the blob store, a temporary directory.
+	Blobs blob.Store
+	// Redis is the in-memory Redis server.
+	Redis *miniredis.Miniredis
+	// Fixture holds the loaded fixtures, see [Harness.User].
+	Fixture *dbfixture.Fixture
+
+	t testing.TB
+}
+
+// New returns a new harness with all fixtures loaded.
+// All resources are released when the test finishes.
+//
+// flags override the test configuration (key => value).
+func New(t testing.TB, flags map[string]any) *Harness {
+	t.Helper()
+
+	ctx := context.Background()
+	h := &Harness{t: t}
+
+	h.Redis = miniredis.RunT(t)
+
+	if err := config.LoadConfig(writeConfig(t), merge(map[string]any{
+		"db.uri":          newDatabase(t),
+		"redis.address":   []string{h.Redis.Addr()},
+		"blob.local.path": filepath.Join(t.TempDir(), "blobs"),
+		// the test servers listen on the loopback interface
+		"blob.allow_private_networks": true,
+	}, flags)); err != nil {
+		t.Fatalf("unable to load test configuration: %v", err)
+	}
+
+	cfg := config.C.Load()
+	log.InitLogging(cfg.Log.Level, cfg.General.Environment)
+
+	if err := models.Connect(); err != nil {
+		t.Fatalf("unable to connect to database: %v", err)
+	}
+
+	h.DB = models.GetDB()
+	t.Cleanup(func() { h.DB.Close() })
+
+	ms, err := migrations.For(models.Dialect())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator := migrate.NewMigrator(h.DB, ms)
+	if err := migrator.Init(ctx); err != nil {
+		t.Fatalf("unable to initialize migrations: %v", err)
+	}
+
+	if _, err := migrator.Migrate(ctx); err != nil {
+		t.Fatalf("unable to migrate database: %v", err)
+	}
+
+	h.DB.RegisterModel(
+		(*models.UserAccount)(nil),
+		(*models.Category)(nil),
+		(*models.Tag)(nil),
+		(*models.Bookmark)(nil),
+		(*models.TagOnBookmark)(nil),
+	)
+
+	h.Fixture = dbfixture.New(h.DB)
+	if err := h.Fixture.Load(ctx, fixtureFS, "fixtures/fixtures.yml"); err != nil {
+		t.Fatalf("unable to load fixtures: %v", err)
+	}
+
+	opt, err := redis.NewClientOption(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// miniredis does not support client side caching
+	opt.DisableCache = true
+
+	rdb, err := rueidis.NewClient(opt)
+	if err != nil {
+		t.Fatalf("unable to connect to redis: %v", err)
+	}
+	t.Cleanup(rdb.Close)
+
+	if err := redis.Connect(rdb); err != nil {
+		t.Fatal(err)
+	}
+
+	if h.Blobs, err = blob.New(ctx, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	h.Store = bunstore.New(h.DB)
+	h.App = handler.NewApp(h.Store, h.Blobs)
+
+	return h
+}
+
+// User returns the user fixture with the given ID, i.e. "alice".
+func (h *Harness) User(id string) models.UserAccount {
+	return *h.Fixture.MustRow("UserAccount." + id).(*models.UserAccount)
+}
+
+// Row returns the fixture with the given ID, i.e. "Tag.alice_go".
+func (h *Harness) Row(id string) any {
+	return h.Fixture.MustRow(id)
+}
+
+// Token returns a signed JWT of the user, like the one issued on login.
+func (h *Harness) Token(userID uuid.UUID) string {
+	h.t.Helper()
+
+	u, err := h.Store.Users.Get(context.Background(), userID)
+	if err != nil {
+		h.t.Fatalf("unable to retrieve user %s: %v", userID, err)
+	}
+
+	t, err := auth.NewToken(u, time.Hour)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+
+	return t
+}
+
+// Request is a request to the API.
+type Request struct {
+	Method string
+	Path   string
+	// Token is sent as bearer token, if it is not empty.
+	Token string
+	// Body is encoded as JSON, if it is not nil.
+	Body any
+	// RawBody is sent as is, if Body is nil.
+	// The content type must be set in Header.
+	RawBody []byte
+	// Header holds additional headers.
+	Header http.Header
+}
+
+// Response is the response of the API.
+type Response struct {
+	*http.Response
+
+	// Body is the read response body.
+	Body []byte
+}
+
+// Decode decodes the JSON body into v.
+func (r Response) Decode(t testing.TB, v any) {
+	t.Helper()
+
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		t.Fatalf("unable to decode response body %q: %v", r.Body, err)
+	}
+}
+
+// Do sends the request to the app.
+func (h *Harness) Do(req Request) Response {
+	h.t.Helper()
+
+	var body io.Reader
+	if req.Body != nil {
+		data, err := json.Marshal(req.Body)
+		if err != nil {
+			h.t.Fatalf("unable to encode request body: %v", err)
+		}
+
+		body = bytes.NewReader(data)
+	} else if req.RawBody != nil {
+		body = bytes.NewReader(req.RawBody)
+	}
+
+	r := httptest.NewRequest(req.Method, req.Path, body)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+
+	if req.Body != nil {
+		r.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+
+	if req.Token != "" {
+		r.Header.Set(fiber.HeaderAuthorization, "Bearer "+req.Token)
+	}
+
+	resp, err := h.App.Test(r, -1)
+	if err != nil {
+		h.t.Fatalf("%s %s: %v", req.Method, req.Path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		h.t.Fatalf("%s %s: unable to read body: %v", req.Method, req.Path, err)
+	}
+
+	return Response{Response: resp, Body: data}
+}
+
+// writeConfig writes the test configuration file and returns its path.
+func writeConfig(t testing.TB) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(path, []byte(`
+general:
+  listen: "127.0.0.1:8080"
+  environment: "development"
+  jwt:
+    signing_key: "`+SigningKey+`"
+  cors:
+    allow_origins: ["*"]
+
+# the expected errors of the tests are logged otherwise,
+# the access log is written independent of the level
+log:
+  level: "fatal"
+  access:
+    enabled: false
+
+oauth:
+  endpoint: "http://127.0.0.1:8080"
+`), 0o600)
+	if err != nil {
+		t.Fatalf("unable to write test configuration: %v", err)
+	}
+
+	return path
+}
+
+// newDatabase returns the URI of a new, empty database.
+//
+// If DBURIEnv is set, a schema is created in that database, which is dropped
+// when the test finishes. Otherwise a temporary SQLite database is used.
+func newDatabase(t testing.TB) string {
+	t.Helper()
+
+	uri := os.Getenv(DBURIEnv)
+	if uri == "" {
+		return "sqlite:" + filepath.Join(t.TempDir(), "briefkasten.db")
+	}
+
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+
+	schema := "test_" + hex.EncodeToString(b)
+
+	admin, err := newPostgres(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		admin.Close()
+		t.Fatalf("unable to create test schema: %v", err)
+	}
+
+	t.Cleanup(func() {
+		defer admin.Close()
+
+		if _, err := admin.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("unable to drop test schema %s: %v", schema, err)
+		}
+	})
+
+	// unknown parameters of the URI are passed to the server,
+	// all connections of the app therefore use the new schema
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", DBURIEnv, err)
+	}
+
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// merge returns the union of both maps, values of b take precedence.
+func merge(a, b map[string]any) map[string]any {
+	for k, v := range b {
+		a[k] = v
+	}
+
+	return a
+}
+
+// newPostgres opens a connection pool to the Postgres database.
+func newPostgres(uri string) (*sql.DB, error) {
+	db := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(uri)))
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "unable to connect to test database")
+	}
+
+	return db, nil
+}
@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// Entry is a single audit trail entry.
+type Entry struct {
+	// Actor is the user or system component which performed the action,
+	// i.e. "signal:SIGHUP".
+	Actor string
+	// Action is the performed action, i.e. "log.level".
+	Action string
+	// OldValue is the value before the action has been performed, if any.
+	OldValue any
+	// NewValue is the value after the action has been performed, if any.
+	NewValue any
+}
+
+// Record writes the entry to the audit trail.
+//
+// The entry is always logged. Additionally it is stored in the database,
+// if this fails only an error is logged, so that an action is never
+// blocked by the audit trail.
+func Record(ctx context.Context, e Entry) {
+	if err := Store(ctx, e); err != nil {
+		log.Error("Unable to store audit log entry", zap.Error(err), zap.String("action", e.Action))
+	}
+}
+
+// Store writes the entry to the audit trail like [Record], but returns
+// the error if the entry can not be stored in the database.
+//
+// It is used for actions, which must not be performed without an entry
+// in the audit trail. The entry must therefore be stored before the action.
+func Store(ctx context.Context, e Entry) error {
+	entry := models.AuditLog{
+		Actor:    e.Actor,
+		Action:   e.Action,
+		OldValue: encodeValue(e.OldValue),
+		NewValue: encodeValue(e.NewValue),
+	}
+
+	log.Info("Audit",
+		zap.Bool("audit", true),
+		zap.String("actor", entry.Actor),
+		zap.String("action", entry.Action),
+		zap.String("old_value", entry.OldValue.String),
+		zap.String("new_value", entry.NewValue.String),
+	)
+
+	return entry.Create(ctx)
+}
+
+// encodeValue returns the JSON representation of the value.
+func encodeValue(v any) null.String {
+	if v == nil {
+		return null.String{}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return null.StringFrom(err.Error())
+	}
+
+	return null.StringFrom(string(data))
+}
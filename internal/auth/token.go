@@ -0,0 +1,41 @@
+// Package auth issues the tokens used to authenticate API requests.
+package auth
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// Claims of the issued tokens.
+const (
+	// ClaimRole is the role of the user.
+	ClaimRole = "role"
+	// ClaimGeneration is the token generation of the user, see [models.UserAccount.TokenGeneration].
+	ClaimGeneration = "gen"
+)
+
+// SessionTTL is the lifetime of the tokens issued on login.
+const SessionTTL = 72 * time.Hour
+
+// NewToken returns a signed JWT of the user, which expires after the given duration.
+func NewToken(u models.UserAccount, ttl time.Duration) (string, error) {
+	cfg := config.C.Load()
+
+	claims := jwt.MapClaims{
+		"user_id":       u.ID.String(),
+		ClaimRole:       u.Role,
+		ClaimGeneration: u.TokenGeneration,
+		"exp":           time.Now().Add(ttl).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(cfg.General.JWT.SigningMethod), claims)
+
+	t, err := token.SignedString([]byte(cfg.General.JWT.SigningKey))
+
+	return t, errors.Wrap(err, "unable to sign JWT token")
+}
@@ -0,0 +1,80 @@
+// Package avatar converts uploaded and imported images to avatars.
+package avatar
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"  // register the GIF decoder
+	_ "image/jpeg" // register the JPEG decoder
+	"image/png"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/image/draw"
+)
+
+const (
+	// Size is the width and height of the avatars in pixels.
+	Size = 256
+	// MaxFileSize is the maximum size of an uploaded image in bytes.
+	MaxFileSize = 2 << 20
+	// maxPixels is the maximum number of pixels of a decoded image,
+	// which prevents decompression bombs.
+	maxPixels = 4096 * 4096
+)
+
+// Ext is the file extension of the avatars.
+const Ext = ".png"
+
+// ErrInvalidImage is returned if the image can not be decoded or is too large.
+var ErrInvalidImage = errors.New("invalid image")
+
+// Convert decodes the image, crops it to a square and scales it to [Size].
+// The avatar is encoded as PNG.
+func Convert(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxFileSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read image")
+	}
+
+	if len(data) > MaxFileSize {
+		return nil, errors.Wrap(ErrInvalidImage, "image is too large")
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidImage, "unable to decode image: %v", err)
+	}
+
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, errors.Wrapf(ErrInvalidImage, "image has too many pixels (%dx%d)", cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidImage, "unable to decode image: %v", err)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, Size, Size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, square(src.Bounds()), draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, errors.Wrap(err, "unable to encode avatar")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// square returns the largest centered square of r.
+func square(r image.Rectangle) image.Rectangle {
+	size := r.Dx()
+	if r.Dy() < size {
+		size = r.Dy()
+	}
+
+	x := r.Min.X + (r.Dx()-size)/2
+	y := r.Min.Y + (r.Dy()-size)/2
+
+	return image.Rect(x, y, x+size, y+size)
+}
@@ -0,0 +1,128 @@
+// Package blob stores binary objects, like images.
+//
+// Blobs are content-addressed: the key of a blob is derived from its
+// content, so that a blob is never changed once it has been stored and
+// identical content is only stored once.
+//
+// Records reference blobs by a [Ref] ("blob:<key>") instead of a URL.
+// The references are resolved to signed URLs of the blob endpoint when
+// the records are returned by the API, see [Resolve].
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+// ErrNotFound is returned if the requested blob does not exist.
+var ErrNotFound = errors.New("blob not found")
+
+// RefPrefix is the prefix of the blob references.
+const RefPrefix = "blob:"
+
+// Info describes a stored blob.
+type Info struct {
+	Key  string
+	Size int64
+	// ModTime is the time the blob has been stored the last time.
+	ModTime time.Time
+}
+
+// Store stores the blobs.
+type Store interface {
+	// Put stores the data with the given key.
+	// Storing an existing key only updates the modification time, since the content is the same.
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns the data of the blob, the caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete deletes the blob, deleting an unknown key is not an error.
+	Delete(ctx context.Context, key string) error
+	// List calls fn for every stored blob.
+	List(ctx context.Context, fn func(Info) error) error
+}
+
+// keyPattern matches valid keys, see [Key].
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{64}\.[a-z0-9]+$`)
+
+// Key returns the key of the data, which is the hex encoded SHA-256
+// hash followed by the extension of the content type, i.e. "<hash>.png".
+func Key(data []byte, ext string) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]) + ext
+}
+
+// ValidKey returns true if key is a valid key, see [Key].
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
+
+// Ref returns the reference to the blob, which is stored in the records.
+func Ref(key string) string {
+	return RefPrefix + key
+}
+
+// KeyOf returns the key of the blob reference.
+// It returns false if ref is no valid reference, i.e. a remote URL.
+func KeyOf(ref string) (string, bool) {
+	key := strings.TrimPrefix(ref, RefPrefix)
+	if key == ref || !ValidKey(key) {
+		return "", false
+	}
+
+	return key, true
+}
+
+// IsRef returns true if s looks like a blob reference, even if it is invalid.
+func IsRef(s string) bool {
+	return strings.HasPrefix(s, RefPrefix)
+}
+
+// ContentType returns the content type of the blob with the given key.
+func ContentType(key string) string {
+	if t := mime.TypeByExtension(path.Ext(key)); t != "" {
+		return t
+	}
+
+	return "application/octet-stream"
+}
+
+// imageExts are the extensions of the supported image types.
+var imageExts = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// ImageExt returns the extension of the image data.
+// It returns false if the data is no supported image.
+func ImageExt(data []byte) (string, bool) {
+	ext, ok := imageExts[http.DetectContentType(data)]
+
+	return ext, ok
+}
+
+// New returns the store of the configured backend.
+func New(ctx context.Context, cfg *config.Config) (Store, error) {
+	switch cfg.Blob.Backend {
+	case "local":
+		return NewLocal(cfg.Blob.Local.Path)
+	case "s3":
+		return NewS3(ctx, cfg.Blob.S3)
+	default:
+		return nil, errors.Errorf("unsupported blob backend %q", cfg.Blob.Backend)
+	}
+}
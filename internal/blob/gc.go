@@ -0,0 +1,50 @@
+package blob
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// GCResult is the result of a garbage collection.
+type GCResult struct {
+	// Scanned is the number of stored blobs.
+	Scanned int `json:"scanned"`
+	// Deleted is the number of deleted (or, in a dry run, deletable) blobs.
+	Deleted int `json:"deleted"`
+	// Freed is the size of the deleted blobs in bytes.
+	Freed int64 `json:"freed"`
+}
+
+// GC deletes all blobs, which are not referenced and older than minAge.
+// The age protects blobs, which have been stored by running requests,
+// but are not referenced yet.
+//
+// No blob is deleted if dryRun is true.
+func GC(ctx context.Context, s Store, referenced map[string]struct{}, minAge time.Duration, dryRun bool) (GCResult, error) {
+	var res GCResult
+
+	deadline := time.Now().Add(-minAge)
+
+	err := s.List(ctx, func(info Info) error {
+		res.Scanned++
+
+		if _, ok := referenced[info.Key]; ok || info.ModTime.After(deadline) {
+			return nil
+		}
+
+		if !dryRun {
+			if err := s.Delete(ctx, info.Key); err != nil {
+				return err
+			}
+		}
+
+		res.Deleted++
+		res.Freed += info.Size
+
+		return ctx.Err()
+	})
+
+	return res, errors.Wrap(err, "unable to collect garbage")
+}
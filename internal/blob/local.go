@@ -0,0 +1,125 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// local stores the blobs in a directory of the local filesystem.
+type local struct {
+	root string
+}
+
+var _ Store = (*local)(nil)
+
+// NewLocal returns a store using the given directory, which is created if it does not exist.
+//
+// The blobs are distributed over sub-directories named by the first two characters
+// of their keys, so that a single directory does not become too large.
+func NewLocal(root string) (Store, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, errors.Wrap(err, "unable to create blob directory")
+	}
+
+	return &local{root: root}, nil
+}
+
+// Put implements [Store].
+func (l *local) Put(_ context.Context, key string, data []byte) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	// the modification time protects recently stored blobs from the garbage collection
+	if now := time.Now(); os.Chtimes(p, now, now) == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return errors.Wrap(err, "unable to create blob directory")
+	}
+
+	// the blob is renamed when it is complete, readers never see a partial blob
+	f, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return errors.Wrap(err, "unable to create blob file")
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return errors.Wrap(err, "unable to write blob")
+	}
+
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "unable to write blob")
+	}
+
+	return errors.Wrap(os.Rename(f.Name(), p), "unable to store blob")
+}
+
+// Get implements [Store].
+func (l *local) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.Wrapf(ErrNotFound, "unable to open blob %s", key)
+	}
+
+	return f, errors.Wrap(err, "unable to open blob")
+}
+
+// Delete implements [Store].
+func (l *local) Delete(_ context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return errors.Wrap(err, "unable to delete blob")
+	}
+
+	return nil
+}
+
+// path returns the path of the blob.
+func (l *local) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", errors.Errorf("invalid blob key %q", key)
+	}
+
+	return filepath.Join(l.root, key[:2], key), nil
+}
+
+// List implements [Store].
+func (l *local) List(_ context.Context, fn func(Info) error) error {
+	err := filepath.WalkDir(l.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !ValidKey(d.Name()) {
+			return err
+		}
+
+		fi, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			// deleted in the meantime
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		return fn(Info{Key: d.Name(), Size: fi.Size(), ModTime: fi.ModTime()})
+	})
+
+	return errors.Wrap(err, "unable to list blobs")
+}
@@ -0,0 +1,124 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+// s3CheckTimeout is the timeout for checking the bucket on startup.
+const s3CheckTimeout = 10 * time.Second
+
+// s3 stores the blobs in a bucket of an S3-compatible object storage.
+type s3 struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+var _ Store = (*s3)(nil)
+
+// NewS3 returns a store using the configured bucket, which must exist.
+func NewS3(ctx context.Context, cfg config.S3) (Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: !cfg.Insecure,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create S3 client")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s3CheckTimeout)
+	defer cancel()
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to check S3 bucket")
+	} else if !exists {
+		return nil, errors.Errorf("S3 bucket %q does not exist", cfg.Bucket)
+	}
+
+	return &s3{client: client, bucket: cfg.Bucket, prefix: cfg.Prefix}, nil
+}
+
+// Put implements [Store].
+func (s *s3) Put(ctx context.Context, key string, data []byte) error {
+	if !ValidKey(key) {
+		return errors.Errorf("invalid blob key %q", key)
+	}
+
+	// the object is uploaded again, so that its modification time is updated
+	_, err := s.client.PutObject(ctx, s.bucket, s.prefix+key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: ContentType(key),
+	})
+
+	return errors.Wrap(err, "unable to upload blob")
+}
+
+// Get implements [Store].
+func (s *s3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !ValidKey(key) {
+		return nil, errors.Errorf("invalid blob key %q", key)
+	}
+
+	obj, err := s.client.GetObject(ctx, s.bucket, s.prefix+key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve blob")
+	}
+
+	// the object is requested lazily, the existence is checked by Stat
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, errors.Wrapf(ErrNotFound, "unable to retrieve blob %s", key)
+		}
+
+		return nil, errors.Wrap(err, "unable to retrieve blob")
+	}
+
+	return obj, nil
+}
+
+// Delete implements [Store].
+func (s *s3) Delete(ctx context.Context, key string) error {
+	if !ValidKey(key) {
+		return errors.Errorf("invalid blob key %q", key)
+	}
+
+	err := s.client.RemoveObject(ctx, s.bucket, s.prefix+key, minio.RemoveObjectOptions{})
+
+	return errors.Wrap(err, "unable to delete blob")
+}
+
+// List implements [Store].
+func (s *s3) List(ctx context.Context, fn func(Info) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: s.prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return errors.Wrap(obj.Err, "unable to list blobs")
+		}
+
+		key := strings.TrimPrefix(obj.Key, s.prefix)
+		if !ValidKey(key) {
+			continue
+		}
+
+		if err := fn(Info{Key: key, Size: obj.Size, ModTime: obj.LastModified}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package blob
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+// PathPrefix is the path of the blob endpoint, followed by the key.
+const PathPrefix = "/api/v1/blobs/"
+
+// ErrInvalidSignature is returned if the signature of a URL is invalid or expired.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// URL returns the signed URL of the blob.
+//
+// The expiry is rounded, so that the URL of a blob stays the same for
+// half of the TTL and browsers are able to cache it.
+// The URL is valid for at least half of the TTL.
+func URL(cfg *config.Config, key string, now time.Time) string {
+	ttl := cfg.Blob.URLTTL
+	expires := strconv.FormatInt(now.Truncate(ttl/2).Add(ttl).Unix(), 10)
+
+	q := url.Values{
+		"expires":   {expires},
+		"signature": {sign(cfg, key, expires)},
+	}
+
+	return baseURL(cfg) + PathPrefix + key + "?" + q.Encode()
+}
+
+// Verify verifies the signature and expiry of the blob URL.
+// It returns the expiry of the URL.
+func Verify(cfg *config.Config, key, expires, signature string, now time.Time) (time.Time, error) {
+	if !validSignature(cfg, key, expires, signature) {
+		return time.Time{}, ErrInvalidSignature
+	}
+
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > exp {
+		return time.Time{}, errors.Wrap(ErrInvalidSignature, "URL has expired")
+	}
+
+	return time.Unix(exp, 0), nil
+}
+
+// Resolve replaces a blob reference by the signed URL of the blob.
+// Other values, like remote URLs, are returned unchanged.
+func Resolve(cfg *config.Config, image null.String, now time.Time) null.String {
+	if key, ok := KeyOf(image.String); ok {
+		return null.StringFrom(URL(cfg, key, now))
+	}
+
+	return image
+}
+
+// RefOfURL returns the reference of a blob URL returned by [URL].
+// The signature must be valid, but the URL may have expired.
+//
+// It returns false if s is no blob URL of this application.
+func RefOfURL(cfg *config.Config, s string) (string, bool, error) {
+	prefix := baseURL(cfg) + PathPrefix
+	if !strings.HasPrefix(s, prefix) {
+		return "", false, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", false, errors.Wrap(err, "invalid blob URL")
+	}
+
+	key := path.Base(u.Path)
+	q := u.Query()
+
+	if !ValidKey(key) || !validSignature(cfg, key, q.Get("expires"), q.Get("signature")) {
+		return "", false, ErrInvalidSignature
+	}
+
+	return Ref(key), true, nil
+}
+
+// baseURL returns the URL of this application.
+func baseURL(cfg *config.Config) string {
+	return strings.TrimSuffix(cfg.OAuth2.Endpoint, "/")
+}
+
+func validSignature(cfg *config.Config, key, expires, signature string) bool {
+	given, err := hex.DecodeString(signature)
+
+	return err == nil && hmac.Equal(given, signBytes(cfg, key, expires))
+}
+
+func sign(cfg *config.Config, key, expires string) string {
+	return hex.EncodeToString(signBytes(cfg, key, expires))
+}
+
+func signBytes(cfg *config.Config, key, expires string) []byte {
+	mac := hmac.New(sha256.New, signingKey(cfg))
+	mac.Write([]byte(key + "\n" + expires))
+
+	return mac.Sum(nil)
+}
+
+// signingKey returns the configured signing key or derives one from the JWT signing key.
+// The derived key differs from the JWT signing key, so that a URL signature
+// can never be used as a JWT signature.
+func signingKey(cfg *config.Config) []byte {
+	if cfg.Blob.SigningKey != "" {
+		return []byte(cfg.Blob.SigningKey)
+	}
+
+	mac := hmac.New(sha256.New, []byte(cfg.General.JWT.SigningKey))
+	mac.Write([]byte("briefkasten blob URL signing key"))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,102 @@
+package blob_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+func TestSignedURL(t *testing.T) {
+	var cfg config.Config
+	cfg.General.JWT.SigningKey = "jwt-signing-key"
+	cfg.OAuth2.Endpoint = "https://briefkasten.example.com/"
+	cfg.Blob.URLTTL = time.Hour
+
+	key := blob.Key([]byte("briefkasten"), ".png")
+	now := time.Date(2023, 4, 4, 12, 10, 0, 0, time.UTC)
+
+	raw := blob.URL(&cfg, key, now)
+	if !strings.HasPrefix(raw, "https://briefkasten.example.com/api/v1/blobs/"+key+"?") {
+		t.Fatalf("unexpected URL %q", raw)
+	}
+
+	// the URL stays the same for half of the TTL
+	if other := blob.URL(&cfg, key, now.Add(15*time.Minute)); other != raw {
+		t.Errorf("expected the same URL, got %q and %q", raw, other)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	otherKey := cfg
+	otherKey.Blob.SigningKey = strings.Repeat("k", 32)
+
+	tests := []struct {
+		name      string
+		cfg       *config.Config
+		key       string
+		expires   string
+		signature string
+		now       time.Time
+		valid     bool
+	}{
+		{name: "valid", cfg: &cfg, key: key, expires: expires, signature: signature, now: now, valid: true},
+		{name: "valid until expiry", cfg: &cfg, key: key, expires: expires, signature: signature, now: now.Add(45 * time.Minute), valid: true},
+		{name: "expired", cfg: &cfg, key: key, expires: expires, signature: signature, now: now.Add(2 * time.Hour)},
+		{name: "other key", cfg: &cfg, key: blob.Key([]byte("other"), ".png"), expires: expires, signature: signature, now: now},
+		{name: "extended expiry", cfg: &cfg, key: key, expires: expires + "0", signature: signature, now: now},
+		{name: "missing signature", cfg: &cfg, key: key, expires: expires, now: now},
+		{name: "other signing key", cfg: &otherKey, key: key, expires: expires, signature: signature, now: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := blob.Verify(tt.cfg, tt.key, tt.expires, tt.signature, tt.now)
+
+			if tt.valid && err != nil {
+				t.Errorf("expected a valid URL, got %v", err)
+			} else if !tt.valid && !errors.Is(err, blob.ErrInvalidSignature) {
+				t.Errorf("expected ErrInvalidSignature, got %v", err)
+			}
+		})
+	}
+
+	t.Run("resolve", func(t *testing.T) {
+		if got := blob.Resolve(&cfg, null.StringFrom(blob.Ref(key)), now); got.String != raw {
+			t.Errorf("expected %q, got %q", raw, got.String)
+		}
+
+		remote := null.StringFrom("https://example.com/image.png")
+		if got := blob.Resolve(&cfg, remote, now); got != remote {
+			t.Errorf("expected the remote URL, got %q", got.String)
+		}
+	})
+
+	t.Run("reference of URL", func(t *testing.T) {
+		// expired URLs are accepted
+		ref, ok, err := blob.RefOfURL(&cfg, blob.URL(&cfg, key, now.Add(-24*time.Hour)))
+		if err != nil || !ok || ref != blob.Ref(key) {
+			t.Errorf("expected reference %q, got %q, %t (%v)", blob.Ref(key), ref, ok, err)
+		}
+
+		if _, ok, err := blob.RefOfURL(&cfg, "https://example.com/image.png"); ok || err != nil {
+			t.Errorf("expected no blob URL, got %t (%v)", ok, err)
+		}
+
+		tampered := strings.Replace(raw, key, blob.Key([]byte("other"), ".png"), 1)
+		if _, _, err := blob.RefOfURL(&cfg, tampered); !errors.Is(err, blob.ErrInvalidSignature) {
+			t.Errorf("expected ErrInvalidSignature, got %v", err)
+		}
+	})
+}
@@ -1,8 +1,9 @@
 package config
 
 import (
-	"io/ioutil"
 	"os"
+	"sync/atomic"
+	"time"
 
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/parsers/yaml"
@@ -27,6 +28,23 @@ type Config struct {
 		//  LevelBestSpeed:        1
 		//  LevelBestCompression:  2
 		CompressionLevel uint `koanf:"compression_level"`
+		// ShutdownDelay is the duration the server keeps handling requests
+		// after the graceful shutdown has been started and the readiness
+		// endpoint reports the shutdown.
+		ShutdownDelay time.Duration `koanf:"shutdown_delay"`
+		// ShutdownTimeout is the maximum duration to wait for open
+		// connections to be closed during the graceful shutdown.
+		ShutdownTimeout time.Duration `koanf:"shutdown_timeout"`
+		// CORS holds the CORS configuration.
+		// It can be changed without a restart.
+		CORS struct {
+			// AllowOrigins is the list of origins which may access the API.
+			AllowOrigins []string `koanf:"allow_origins"`
+		} `koanf:"cors"`
+		// RateLimit holds the rate limit configuration.
+		// The limits are applied per client IP and can be changed without a restart,
+		// but all counters are reset on change.
+		RateLimit RateLimit `koanf:"rate_limit"`
 		// SecureCookie defines if cookies shall be marked as "secure".
 		SecureCookie bool `koanf:"secure_cookie"`
 		// JWT holds the JWT configuration.
@@ -34,78 +52,351 @@ type Config struct {
 			// SigningMethod is the method used to sign/ check JWT tokens.
 			SigningMethod string `koanf:"signing_method"`
 			// SigningKey is the private key to sign JWT tokens.
-			SigningKey string `koanf:"signing_key"`
+			SigningKey string `koanf:"signing_key" redact:"true"`
 		} `koanf:"jwt"`
 	} `koanf:"general"`
+	// Log holds the logging configuration.
+	Log struct {
+		// Level is the log level.
+		// The following values can be used: debug, info, warn, error, fatal
+		Level string `koanf:"level"`
+		// Access holds the configuration of the HTTP access log.
+		Access struct {
+			// Enabled enables the access log.
+			// It is written independent of the log level.
+			Enabled bool `koanf:"enabled"`
+			// SkipAnonymous disables the access log for anonymous routes,
+			// like the health checks.
+			SkipAnonymous bool `koanf:"skip_anonymous"`
+			// SampleRates is the ratio (between 0 and 1) of logged requests
+			// per route, i.e. "/api/v1/users/:id/tags: 0.1".
+			// Routes without a sample rate are always logged.
+			SampleRates map[string]float64 `koanf:"sample_rates"`
+		} `koanf:"access"`
+	} `koanf:"log"`
+	// ErrorReporting holds the configuration of the error reporting
+	// of panics and server errors.
+	ErrorReporting struct {
+		// Reporter is the error reporter.
+		// The following values can be used: none, log, sentry
+		Reporter string `koanf:"reporter"`
+		// DSN is the Sentry DSN, i.e. "https://<key>@sentry.example.com/<project>".
+		DSN string `koanf:"dsn" redact:"true"`
+	} `koanf:"error_reporting"`
 	Debug struct {
 		EnableSQLDebug bool `koanf:"enable_sql_debug"`
 		EnableTracing  bool `koanf:"enable_tracing"`
-		Tracing        struct {
+		// EnableRedisTracing creates a span for every redis command.
+		// It requires EnableTracing to be set.
+		EnableRedisTracing bool `koanf:"enable_redis_tracing"`
+		Tracing            struct {
+			// Exporter is the span exporter.
+			// The following values can be used: otlp-grpc, otlp-http, stdout
+			Exporter string `koanf:"exporter"`
 			// Tracing endpoint. For example host+port of Jaeger.
 			Endpoint string `koanf:"endpoint"`
+			// Insecure disables TLS for the OTLP exporters.
+			Insecure bool `koanf:"insecure"`
+			// SampleRatio is the ratio of sampled traces, between 0 and 1.
+			// Traces with a sampled parent are always sampled.
+			SampleRatio float64 `koanf:"sample_ratio"`
+			// ServiceName is the name of the service reported in all spans.
+			ServiceName string `koanf:"service_name"`
+			// ResourceAttributes are additional resource attributes in
+			// the "key=value" format, i.e. "deployment.environment=staging".
+			ResourceAttributes []string `koanf:"resource_attributes"`
+			// Propagators are the propagators used to extract the trace context
+			// of callers from the request headers.
+			// The following values can be used: tracecontext, baggage, b3, b3multi
+			Propagators []string `koanf:"propagators"`
+			// DBName is the database name reported in all database spans.
+			DBName string `koanf:"db_name"`
 		} `koanf:"tracing"`
 	} `koanf:"debug"`
+	// Internal holds the configuration of the internal listener.
+	// It serves all non-public endpoints, like the metrics.
+	Internal struct {
+		// Listen is the IP and port where the internal listener should listen to.
+		// The internal listener is disabled if it is empty.
+		Listen string `koanf:"listen"`
+		// AdminToken is the bearer token required to access the admin endpoints.
+		// The admin endpoints are disabled if it is empty.
+		AdminToken string `koanf:"admin_token" redact:"true"`
+		// EnablePprof exposes the pprof endpoints at "/debug/pprof".
+		EnablePprof bool `koanf:"enable_pprof"`
+	} `koanf:"internal"`
+	// Metrics holds the prometheus metrics configuration.
+	Metrics struct {
+		// Enabled enables the collection of metrics.
+		// The metrics are exposed at "/metrics" of the internal listener.
+		Enabled bool `koanf:"enabled"`
+		// StatsInterval is the interval of refreshing the business statistics,
+		// like the number of users.
+		StatsInterval time.Duration `koanf:"stats_interval"`
+	} `koanf:"metrics"`
 	// DB holds the database specific configuration parameter.
 	DB struct {
 		// URI is the connection URI.
-		URI string `koanf:"uri"`
+		// The scheme selects the database, "postgres://..." for Postgres and
+		// "sqlite:<path>" (i.e. "sqlite:/var/lib/briefkasten/briefkasten.db"
+		// or "sqlite::memory:") for SQLite.
+		//
+		// NOTE: The TLS, pool and timeout settings only apply to Postgres.
+		URI string `koanf:"uri" redact:"url"`
+		// TLS holds the TLS configuration.
+		TLS struct {
+			// Mode is the SSL mode, like the sslmode of libpq.
+			// The following values can be used: disable, require, verify-ca, verify-full
+			// The sslmode of the URI is used if it is empty.
+			Mode string `koanf:"mode"`
+			// CAFile is the path to the PEM encoded CA certificates used to verify
+			// the server certificate. The system CA pool is used if it is empty.
+			CAFile string `koanf:"ca_file"`
+			// CertFile is the path to the PEM encoded client certificate.
+			CertFile string `koanf:"cert_file"`
+			// KeyFile is the path to the PEM encoded private key of the client certificate.
+			KeyFile string `koanf:"key_file"`
+		} `koanf:"tls"`
+		// Pool holds the connection pool configuration.
+		Pool struct {
+			// MaxOpenConns is the maximum number of open connections.
+			// Zero means unlimited.
+			MaxOpenConns int `koanf:"max_open_conns"`
+			// MaxIdleConns is the maximum number of idle connections.
+			MaxIdleConns int `koanf:"max_idle_conns"`
+			// ConnMaxLifetime is the maximum duration a connection is reused.
+			// Zero means unlimited.
+			ConnMaxLifetime time.Duration `koanf:"conn_max_lifetime"`
+			// ConnMaxIdleTime is the maximum duration a connection may be idle.
+			// Zero means unlimited.
+			ConnMaxIdleTime time.Duration `koanf:"conn_max_idle_time"`
+		} `koanf:"pool"`
+		// DialTimeout is the timeout for establishing a connection.
+		DialTimeout time.Duration `koanf:"dial_timeout"`
+		// ReadTimeout is the timeout for reading a response of the database.
+		// It must be greater than the StatementTimeout.
+		ReadTimeout time.Duration `koanf:"read_timeout"`
+		// StatementTimeout aborts statements taking longer than the timeout.
+		// It is set on every connection, zero disables the timeout.
+		StatementTimeout time.Duration `koanf:"statement_timeout"`
+		// LockTimeout aborts statements waiting longer than the timeout for a lock.
+		// It is set on every connection, zero disables the timeout.
+		LockTimeout time.Duration `koanf:"lock_timeout"`
+		// StartupTimeout is the maximum duration to wait for the database
+		// to become reachable during the startup.
+		StartupTimeout time.Duration `koanf:"startup_timeout"`
+		// AutoMigrate applies all unapplied migrations on startup.
+		// The server refuses to start with unapplied migrations otherwise.
+		AutoMigrate bool `koanf:"auto_migrate"`
 	} `koanf:"db"`
 	// Redis holds the redis specific configuration parameter.
 	Redis struct {
+		// Mode is the deployment mode of redis.
+		// The following values can be used: standalone, sentinel, cluster
+		Mode string `koanf:"mode"`
 		// Network defines the communication type.
+		// Only "tcp" is supported.
 		Network string `koanf:"network"`
 		// Address is the host and port of the redis servers.
+		// In sentinel mode, these are the addresses of the sentinels,
+		// in cluster mode the addresses used to discover the cluster nodes.
 		Address []string `koanf:"address"`
+		// Username is the ACL username.
+		// It can be left blank to authenticate as the default user.
+		Username string `koanf:"username"`
 		// Password is the authentication password.
 		// It can be left blank.
-		Password string `koanf:"password"`
+		Password string `koanf:"password" redact:"true"`
 		// DB is the database number which should be used.
+		// It must be 0 in cluster mode.
 		DB int `koanf:"db"`
+		// DialTimeout is the timeout for establishing a connection.
+		DialTimeout time.Duration `koanf:"dial_timeout"`
+		// TLS holds the TLS configuration.
+		TLS TLS `koanf:"tls"`
+		// Sentinel holds the sentinel configuration, used in sentinel mode.
+		Sentinel struct {
+			// MasterSet is the name of the master set monitored by the sentinels.
+			MasterSet string `koanf:"master_set"`
+			// Username is the ACL username of the sentinels.
+			Username string `koanf:"username"`
+			// Password is the authentication password of the sentinels.
+			Password string `koanf:"password" redact:"true"`
+		} `koanf:"sentinel"`
 	} `koanf:"redis"`
+	// Blob holds the configuration of the blob storage,
+	// which stores images like the avatars of the users.
+	Blob struct {
+		// Backend is the storage backend.
+		// The following values can be used: local, s3
+		Backend string `koanf:"backend"`
+		// Local holds the configuration of the local backend.
+		Local struct {
+			// Path is the directory of the blobs.
+			Path string `koanf:"path"`
+		} `koanf:"local"`
+		// S3 holds the configuration of the S3 backend.
+		S3 S3 `koanf:"s3"`
+		// SigningKey is the key used to sign the URLs of the blobs.
+		// A key derived from the JWT signing key is used if it is empty.
+		SigningKey string `koanf:"signing_key" redact:"true"`
+		// URLTTL is the minimum lifetime of the signed URLs.
+		URLTTL time.Duration `koanf:"url_ttl"`
+		// AllowPrivateNetworks allows importing images from loopback, private and
+		// link-local addresses. It must only be enabled if all users are trusted,
+		// since they are able to access internal services otherwise.
+		AllowPrivateNetworks bool `koanf:"allow_private_networks"`
+		// MaintenanceInterval is the interval of importing remote bookmark
+		// images and deleting unreferenced blobs. Zero disables it.
+		MaintenanceInterval time.Duration `koanf:"maintenance_interval"`
+		// GCMinAge is the minimum age of unreferenced blobs before they are deleted,
+		// so that blobs of running requests are not deleted.
+		GCMinAge time.Duration `koanf:"gc_min_age"`
+	} `koanf:"blob"`
 	// OAuth2 holds the oauth2 information used for the dev portal.
 	OAuth2 struct {
 		// Endpoint is the endpoint/ URL of this application.
 		Endpoint string `koanf:"endpoint"`
+		// Providers is the list of enabled providers, i.e. "github".
+		// All providers are enabled if it is empty.
+		// It can be changed without a restart.
+		Providers []string `koanf:"providers"`
 	} `koanf:"oauth"`
 }
 
+// S3 is the configuration of an S3-compatible object storage.
+type S3 struct {
+	// Endpoint is the host and port of the storage, i.e. "s3.eu-central-1.amazonaws.com".
+	Endpoint string `koanf:"endpoint"`
+	// Region is the region of the bucket.
+	// It is detected if it is empty.
+	Region string `koanf:"region"`
+	// Bucket is the name of the bucket, which must exist.
+	Bucket string `koanf:"bucket"`
+	// Prefix is prepended to the keys of all objects, i.e. "blobs/".
+	Prefix string `koanf:"prefix"`
+	// AccessKey is the access key ID.
+	AccessKey string `koanf:"access_key"`
+	// SecretKey is the secret access key.
+	SecretKey string `koanf:"secret_key" redact:"true"`
+	// Insecure disables TLS.
+	Insecure bool `koanf:"insecure"`
+}
+
+// RateLimit is the rate limit configuration.
+type RateLimit struct {
+	// Enabled enables the rate limit.
+	Enabled bool `koanf:"enabled"`
+	// Max is the maximum number of requests per client within the expiration.
+	Max int `koanf:"max"`
+	// Expiration is the time window of the rate limit.
+	Expiration time.Duration `koanf:"expiration"`
+}
+
 // C holds the current configuration.
-var C Config
+//
+// The configuration is swapped atomically on reload, callers which need
+// multiple values should therefore load it only once.
+var C atomic.Pointer[Config]
 
-var (
-	k      = koanf.New(".")
-	parser = yaml.Parser()
-)
+var parser = yaml.Parser()
+
+// LoadConfig loads, parses and validates the configuration from the given path.
+// See [Parse] for the precedence of the configuration sources.
+func LoadConfig(path string, flags map[string]any) error {
+	cfg, err := Parse(path, flags)
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return errors.Wrap(err, "invalid configuration")
+	}
 
-// LoadConfig loads and parses the configuration from the given path.
-func LoadConfig(path string) error {
-	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
-		if err = ioutil.WriteFile(path, []byte(""), 0o644); err != nil {
-			return errors.Wrap(err, "unable to create config file")
-		}
-	} else if err != nil {
-		return errors.Wrap(err, "unable to create config file")
+	C.Store(&cfg)
+
+	return nil
+}
+
+// Parse loads and parses the configuration from the given path,
+// without changing the current configuration.
+//
+// The configuration is merged from the following sources, where
+// later sources override earlier ones:
+//
+//  1. default values
+//  2. configuration file
+//  3. environment variables (see [EnvPrefix])
+//  4. command line flags, passed as flags (key => value)
+func Parse(path string, flags map[string]any) (Config, error) {
+	var cfg Config
+
+	if _, err := os.Stat(path); err != nil {
+		return cfg, errors.Wrap(err, "unable to access config file")
 	}
 
-	loadDefaultValues()
+	k := koanf.New(".")
+
+	loadDefaultValues(k)
 
 	if err := k.Load(file.Provider(path), parser); err != nil {
-		return errors.Wrap(err, "unable to load file from path")
+		return cfg, errors.Wrap(err, "unable to load file from path")
 	}
 
-	if err := k.Unmarshal("", &C); err != nil {
-		return errors.Wrap(err, "unable to parse configuration file")
+	envs, err := envValues(os.Environ())
+	if err != nil {
+		return cfg, err
 	}
 
-	if C.General.JWT.SigningKey == "" {
-		return errors.New("JWT signing key not provided")
+	if err := k.Load(confmap.Provider(envs, "."), nil); err != nil {
+		return cfg, errors.Wrap(err, "unable to load environment variables")
 	}
 
-	return nil
+	if err := k.Load(confmap.Provider(flags, "."), nil); err != nil {
+		return cfg, errors.Wrap(err, "unable to load flags")
+	}
+
+	if err := k.Unmarshal("", &cfg); err != nil {
+		return cfg, errors.Wrap(err, "unable to parse configuration file")
+	}
+
+	return cfg, nil
 }
 
-func loadDefaultValues() {
+func loadDefaultValues(k *koanf.Koanf) {
 	k.Load(confmap.Provider(map[string]any{
-		"general.jwt.signing_method": "HS256",
+		"general.jwt.signing_method":    "HS256",
+		"general.shutdown_delay":        "5s",
+		"general.shutdown_timeout":      "10s",
+		"metrics.stats_interval":        "1m",
+		"log.level":                     "info",
+		"db.pool.max_open_conns":        20,
+		"db.pool.max_idle_conns":        5,
+		"db.pool.conn_max_lifetime":     "30m",
+		"db.pool.conn_max_idle_time":    "5m",
+		"db.dial_timeout":               "5s",
+		"db.read_timeout":               "35s",
+		"db.statement_timeout":          "30s",
+		"db.lock_timeout":               "10s",
+		"db.startup_timeout":            "1m",
+		"redis.mode":                    "standalone",
+		"redis.network":                 "tcp",
+		"redis.dial_timeout":            "5s",
+		"general.cors.allow_origins":    []string{"*"},
+		"general.rate_limit.max":        100,
+		"general.rate_limit.expiration": "1m",
+		"error_reporting.reporter":      "log",
+		"log.access.enabled":            true,
+		"log.access.skip_anonymous":     true,
+		"debug.tracing.exporter":        "otlp-grpc",
+		"debug.tracing.sample_ratio":    1.0,
+		"debug.tracing.service_name":    "briefkasten",
+		"debug.tracing.db_name":         "briefkasten",
+		"debug.tracing.propagators":     []string{"tracecontext", "baggage"},
+		"blob.backend":                  "local",
+		"blob.local.path":               "data/blobs",
+		"blob.url_ttl":                  "1h",
+		"blob.maintenance_interval":     "6h",
+		"blob.gc_min_age":               "24h",
 	}, "."), nil)
 }
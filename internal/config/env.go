@@ -0,0 +1,125 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// EnvPrefix is the prefix of all environment variables overriding configuration keys.
+//
+// The environment variable of a key is the upper-case key with all dots replaced
+// by underscores, i.e. BRIEFKASTEN_GENERAL_JWT_SIGNING_KEY for general.jwt.signing_key.
+// If the variable name is suffixed with _FILE, the value is read from the file
+// at the given path instead, i.e. BRIEFKASTEN_REDIS_PASSWORD_FILE=/run/secrets/redis.
+//
+// Lists are separated by commas ("a,b") and maps are written as
+// comma separated key-value pairs ("a=1,b=2").
+const EnvPrefix = "BRIEFKASTEN_"
+
+// legacyDBEnv is the deprecated environment variable of the database URI.
+// Use BRIEFKASTEN_DB_URI instead.
+const legacyDBEnv = "DB_CONNECTION"
+
+// fileSuffix is the suffix of environment variables pointing to a file.
+const fileSuffix = "_FILE"
+
+// configKey describes a single configuration key.
+type configKey struct {
+	name string
+	kind reflect.Kind
+}
+
+// envValues returns all configuration values set via environment variables.
+func envValues(environ []string) (map[string]any, error) {
+	keys := make(map[string]configKey)
+	for _, key := range collectKeys(reflect.TypeOf(Config{}), "") {
+		keys[EnvPrefix+strings.ToUpper(strings.ReplaceAll(key.name, ".", "_"))] = key
+	}
+
+	ret := make(map[string]any)
+
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+
+		key, ok := keys[name]
+		if !ok {
+			if key, ok = keys[strings.TrimSuffix(name, fileSuffix)]; !ok || !strings.HasSuffix(name, fileSuffix) {
+				continue
+			}
+
+			data, err := os.ReadFile(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to read file of environment variable %s", name)
+			}
+
+			value = strings.TrimRight(string(data), "\r\n")
+		}
+
+		ret[key.name] = parseEnvValue(key.kind, value)
+	}
+
+	// DB_CONNECTION is supported for backwards compatibility
+	if _, ok := ret["db.uri"]; !ok {
+		for _, kv := range environ {
+			if name, value, _ := strings.Cut(kv, "="); name == legacyDBEnv && value != "" {
+				ret["db.uri"] = value
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// parseEnvValue converts lists and maps, all other values are
+// converted while unmarshalling the configuration.
+func parseEnvValue(kind reflect.Kind, value string) any {
+	switch kind {
+	case reflect.Slice:
+		if value == "" {
+			return []string{}
+		}
+
+		return strings.Split(value, ",")
+	case reflect.Map:
+		m := make(map[string]any)
+
+		for _, pair := range strings.Split(value, ",") {
+			if k, v, ok := strings.Cut(pair, "="); ok {
+				m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+
+		return m
+	default:
+		return value
+	}
+}
+
+// collectKeys returns all configuration keys of the struct type.
+func collectKeys(t reflect.Type, prefix string) []configKey {
+	var keys []configKey
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("koanf")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		name := prefix + tag
+
+		if f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeOf(time.Duration(0)) {
+			keys = append(keys, collectKeys(f.Type, name+".")...)
+			continue
+		}
+
+		keys = append(keys, configKey{name: name, kind: f.Type.Kind()})
+	}
+
+	return keys
+}
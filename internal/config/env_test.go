@@ -0,0 +1,153 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEnvValues(t *testing.T) {
+	dir := t.TempDir()
+
+	secret := filepath.Join(dir, "redis-password")
+	if err := os.WriteFile(secret, []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		environ []string
+		want    map[string]any
+		err     bool
+	}{
+		{
+			name:    "nested key",
+			environ: []string{"BRIEFKASTEN_GENERAL_JWT_SIGNING_KEY=key"},
+			want:    map[string]any{"general.jwt.signing_key": "key"},
+		},
+		{
+			name:    "key with underscores",
+			environ: []string{"BRIEFKASTEN_DB_STARTUP_TIMEOUT=2m"},
+			want:    map[string]any{"db.startup_timeout": "2m"},
+		},
+		{
+			name:    "list",
+			environ: []string{"BRIEFKASTEN_REDIS_ADDRESS=a:6379,b:6379"},
+			want:    map[string]any{"redis.address": []string{"a:6379", "b:6379"}},
+		},
+		{
+			name:    "empty list",
+			environ: []string{"BRIEFKASTEN_GENERAL_CORS_ALLOW_ORIGINS="},
+			want:    map[string]any{"general.cors.allow_origins": []string{}},
+		},
+		{
+			name:    "map",
+			environ: []string{"BRIEFKASTEN_LOG_ACCESS_SAMPLE_RATES=/a=0.5, /b = 1"},
+			want:    map[string]any{"log.access.sample_rates": map[string]any{"/a": "0.5", "/b": "1"}},
+		},
+		{
+			name:    "unknown and foreign variables are ignored",
+			environ: []string{"BRIEFKASTEN_UNKNOWN=1", "HOME=/root", "BRIEFKASTEN_LOG=debug"},
+			want:    map[string]any{},
+		},
+		{
+			name:    "secret from file",
+			environ: []string{"BRIEFKASTEN_REDIS_PASSWORD_FILE=" + secret},
+			want:    map[string]any{"redis.password": "s3cr3t"},
+		},
+		{
+			name:    "missing secret file",
+			environ: []string{"BRIEFKASTEN_REDIS_PASSWORD_FILE=" + filepath.Join(dir, "missing")},
+			err:     true,
+		},
+		{
+			name:    "file suffix of an unknown key",
+			environ: []string{"BRIEFKASTEN_UNKNOWN_FILE=" + filepath.Join(dir, "missing")},
+			want:    map[string]any{},
+		},
+		{
+			name:    "legacy database URI",
+			environ: []string{"DB_CONNECTION=postgres://legacy"},
+			want:    map[string]any{"db.uri": "postgres://legacy"},
+		},
+		{
+			name:    "database URI takes precedence over the legacy variable",
+			environ: []string{"DB_CONNECTION=postgres://legacy", "BRIEFKASTEN_DB_URI=postgres://new"},
+			want:    map[string]any{"db.uri": "postgres://new"},
+		},
+		{
+			name:    "empty legacy database URI",
+			environ: []string{"DB_CONNECTION="},
+			want:    map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := envValues(tt.environ)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(path, []byte(`
+general:
+  shutdown_delay: "1s"
+log:
+  level: "warn"
+redis:
+  db: 1
+  address: ["file:6379"]
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("BRIEFKASTEN_LOG_LEVEL", "error")
+	t.Setenv("BRIEFKASTEN_REDIS_DB", "2")
+	t.Setenv("BRIEFKASTEN_REDIS_ADDRESS", "env-a:6379,env-b:6379")
+	t.Setenv("DB_CONNECTION", "postgres://legacy")
+
+	cfg, err := Parse(path, map[string]any{"log.level": "debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"default", cfg.Redis.Mode, "standalone"},
+		{"file overrides default", cfg.General.ShutdownDelay, time.Second},
+		{"environment overrides file", cfg.Redis.DB, 2},
+		{"environment list overrides file", cfg.Redis.Address, []string{"env-a:6379", "env-b:6379"}},
+		{"flag overrides environment", cfg.Log.Level, "debug"},
+		{"legacy environment variable", cfg.DB.URI, "postgres://legacy"},
+	}
+
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, tt.got)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// redactedValue replaces all redacted values.
+const redactedValue = "REDACTED"
+
+// Marshal returns the YAML representation of the configuration.
+// If redacted is set, all secrets (fields tagged with `redact`) are replaced.
+func (c Config) Marshal(redacted bool) ([]byte, error) {
+	m := structToMap(reflect.ValueOf(c), redacted)
+
+	data, err := parser.Marshal(m)
+
+	return data, errors.Wrap(err, "unable to marshal configuration")
+}
+
+// structToMap converts the struct into a map using the koanf tags as keys.
+func structToMap(v reflect.Value, redacted bool) map[string]any {
+	t := v.Type()
+	m := make(map[string]any, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("koanf")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+
+		switch {
+		case f.Type == reflect.TypeOf(time.Duration(0)):
+			m[tag] = time.Duration(fv.Int()).String()
+		case f.Type.Kind() == reflect.Struct:
+			m[tag] = structToMap(fv, redacted)
+		case redacted && f.Tag.Get("redact") != "":
+			m[tag] = redact(f.Tag.Get("redact"), fv)
+		default:
+			m[tag] = fv.Interface()
+		}
+	}
+
+	return m
+}
+
+// redact returns the redacted value.
+// For the "url" mode, only the password of the URL is redacted.
+func redact(mode string, v reflect.Value) any {
+	s, ok := v.Interface().(string)
+	if !ok {
+		return redactedValue
+	}
+
+	if s == "" {
+		return ""
+	}
+
+	if mode == "url" {
+		u, err := url.Parse(s)
+		if err != nil {
+			return redactedValue
+		}
+
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redactedValue)
+		}
+
+		return u.String()
+	}
+
+	return redactedValue
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/knadh/koanf/providers/file"
+	"github.com/pkg/errors"
+)
+
+// reloadDebounce is the time to wait for further changes of the
+// configuration file, because editors often write a file multiple times.
+const reloadDebounce = 500 * time.Millisecond
+
+// reloadMu serializes reloads, i.e. of SIGHUP and the file watcher.
+var reloadMu sync.Mutex
+
+// ReloadResult describes the changes of a configuration reload.
+type ReloadResult struct {
+	// Applied are the changed keys which have been applied.
+	Applied []string
+	// Rejected are the changed keys which require a restart.
+	// Their old values are kept.
+	Rejected []string
+}
+
+// Reload loads the configuration from the given path and applies all
+// changes of keys which can be changed without a restart (see [copyHotFields]).
+// Changes of all other keys are rejected.
+//
+// The new configuration is validated as a whole and stored atomically,
+// so readers never see a partially applied configuration.
+func Reload(path string, flags map[string]any) (ReloadResult, error) {
+	var res ReloadResult
+
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	next, err := Parse(path, flags)
+	if err != nil {
+		return res, err
+	}
+
+	if err := next.Validate(); err != nil {
+		return res, errors.Wrap(err, "invalid configuration")
+	}
+
+	cur := *C.Load()
+	applied := cur
+	copyHotFields(&applied, &next)
+
+	res.Applied = diffKeys(cur, applied)
+	// the rejected keys are the remaining differences after applying the hot keys
+	res.Rejected = diffKeys(applied, next)
+
+	C.Store(&applied)
+
+	return res, nil
+}
+
+// Watch calls onChange whenever the configuration file at the given path changes.
+// Multiple changes within a short time are combined into a single call.
+//
+// If watching fails, onChange is called with the error and the
+// file is not watched anymore (i.e. if the file has been removed).
+func Watch(path string, onChange func(err error)) error {
+	var (
+		mu    sync.Mutex
+		timer *time.Timer
+	)
+
+	err := file.Provider(path).Watch(func(_ any, err error) {
+		if err != nil {
+			onChange(errors.Wrap(err, "stopped watching configuration file"))
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if timer != nil {
+			timer.Stop()
+		}
+
+		timer = time.AfterFunc(reloadDebounce, func() { onChange(nil) })
+	})
+
+	return errors.Wrap(err, "unable to watch configuration file")
+}
+
+// copyHotFields copies all fields which can be changed without a restart from src to dst:
+//
+//   - log.level
+//   - general.cors
+//   - general.rate_limit
+//   - general.enable_compression and general.compression_level
+//   - oauth.providers
+//   - debug.enable_sql_debug
+func copyHotFields(dst, src *Config) {
+	dst.Log.Level = src.Log.Level
+	dst.General.CORS = src.General.CORS
+	dst.General.RateLimit = src.General.RateLimit
+	dst.General.EnableCompression = src.General.EnableCompression
+	dst.General.CompressionLevel = src.General.CompressionLevel
+	dst.OAuth2.Providers = src.OAuth2.Providers
+	dst.Debug.EnableSQLDebug = src.Debug.EnableSQLDebug
+}
+
+// diffKeys returns the sorted keys of all values which differ between a and b.
+func diffKeys(a, b Config) []string {
+	am := flatten(structToMap(reflect.ValueOf(a), false), "")
+	bm := flatten(structToMap(reflect.ValueOf(b), false), "")
+
+	var keys []string
+	for k, v := range am {
+		if !reflect.DeepEqual(v, bm[k]) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// flatten converts the nested map into a map with dotted keys, i.e. "log.level".
+func flatten(m map[string]any, prefix string) map[string]any {
+	out := make(map[string]any, len(m))
+
+	for k, v := range m {
+		if sub, ok := v.(map[string]any); ok {
+			for sk, sv := range flatten(sub, prefix+k+".") {
+				out[sk] = sv
+			}
+
+			continue
+		}
+
+		out[prefix+k] = v
+	}
+
+	return out
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	path := writeTestConfig(t)
+
+	if err := LoadConfig(path, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	old := *C.Load()
+
+	err := os.WriteFile(path, []byte(`
+general:
+  listen: "127.0.0.1:9090"
+  environment: "development"
+  enable_compression: true
+  compression_level: 2
+  cors:
+    allow_origins: ["https://example.com"]
+  rate_limit:
+    enabled: true
+    max: 10
+    expiration: "1s"
+  jwt:
+    signing_key: "config-signing-key-config-signing-key"
+
+log:
+  level: "debug"
+
+oauth:
+  providers: ["github"]
+
+debug:
+  enable_sql_debug: true
+
+db:
+  uri: "sqlite:/tmp/other.db"
+
+redis:
+  address: ["127.0.0.1:6379"]
+
+blob:
+  local:
+    path: "/tmp/blobs"
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Reload(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantApplied := []string{
+		"debug.enable_sql_debug",
+		"general.compression_level",
+		"general.cors.allow_origins",
+		"general.enable_compression",
+		"general.rate_limit.enabled",
+		"general.rate_limit.expiration",
+		"general.rate_limit.max",
+		"log.level",
+		"oauth.providers",
+	}
+	if !reflect.DeepEqual(res.Applied, wantApplied) {
+		t.Errorf("expected the applied keys %v, got %v", wantApplied, res.Applied)
+	}
+
+	if want := []string{"db.uri", "general.listen"}; !reflect.DeepEqual(res.Rejected, want) {
+		t.Errorf("expected the rejected keys %v, got %v", want, res.Rejected)
+	}
+
+	cfg := C.Load()
+
+	// hot fields are applied
+	if cfg.Log.Level != "debug" || !cfg.Debug.EnableSQLDebug || !cfg.General.EnableCompression ||
+		cfg.General.CompressionLevel != 2 || cfg.General.RateLimit.Max != 10 ||
+		cfg.General.RateLimit.Expiration != time.Second || !reflect.DeepEqual(cfg.OAuth2.Providers, []string{"github"}) ||
+		!reflect.DeepEqual(cfg.General.CORS.AllowOrigins, []string{"https://example.com"}) {
+		t.Errorf("expected the hot fields to be applied, got %+v", cfg)
+	}
+
+	// restart-only keys keep their old value
+	if cfg.General.Listen != old.General.Listen || cfg.DB.URI != old.DB.URI {
+		t.Errorf("expected the old listen address and database, got %s and %s", cfg.General.Listen, cfg.DB.URI)
+	}
+
+	// nothing changed
+	if res, err := Reload(path, nil); err != nil || len(res.Applied) != 0 || len(res.Rejected) != 2 {
+		t.Errorf("expected no applied keys, got %+v (%v)", res, err)
+	}
+}
+
+func TestReloadInvalid(t *testing.T) {
+	path := writeTestConfig(t)
+
+	if err := LoadConfig(path, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	old := C.Load()
+
+	if err := os.WriteFile(path, []byte(`
+log:
+  level: "verbose"
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Reload(path, nil); err == nil {
+		t.Fatal("expected an error for an invalid configuration")
+	}
+
+	if C.Load() != old {
+		t.Error("expected the configuration to be unchanged")
+	}
+}
+
+func TestCopyHotFields(t *testing.T) {
+	var src, dst Config
+
+	src.Log.Level = "debug"
+	src.General.CORS.AllowOrigins = []string{"https://example.com"}
+	src.General.RateLimit = RateLimit{Enabled: true, Max: 1, Expiration: time.Second}
+	src.General.EnableCompression = true
+	src.General.CompressionLevel = 2
+	src.OAuth2.Providers = []string{"github"}
+	src.Debug.EnableSQLDebug = true
+
+	// restart-only keys
+	src.General.Listen = "127.0.0.1:8080"
+	src.DB.URI = "sqlite:/tmp/briefkasten.db"
+	src.Redis.Password = "secret"
+
+	copyHotFields(&dst, &src)
+
+	want := []string{"db.uri", "general.listen", "redis.password"}
+	if got := diffKeys(dst, src); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected only the restart-only keys %v to differ, got %v", want, got)
+	}
+}
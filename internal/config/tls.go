@@ -0,0 +1,95 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// TLS is the TLS configuration of a client connection.
+type TLS struct {
+	// Enabled enables TLS.
+	Enabled bool `koanf:"enabled"`
+	// CAFile is the path to the PEM encoded CA certificates used to verify
+	// the server certificate. The system CA pool is used if it is empty.
+	CAFile string `koanf:"ca_file"`
+	// CertFile is the path to the PEM encoded client certificate.
+	// It is only required for mutual TLS.
+	CertFile string `koanf:"cert_file"`
+	// KeyFile is the path to the PEM encoded private key of the client certificate.
+	KeyFile string `koanf:"key_file"`
+	// ServerName overrides the name used to verify the server certificate.
+	ServerName string `koanf:"server_name"`
+	// InsecureSkipVerify disables the verification of the server certificate.
+	// It must only be used for testing.
+	InsecureSkipVerify bool `koanf:"insecure_skip_verify"`
+}
+
+// Config returns the [tls.Config] of the configuration.
+// Nil is returned if TLS is disabled.
+func (t TLS) Config() (*tls.Config, error) {
+	if !t.Enabled {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify, //nolint:gosec // explicitly configured
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read CA file")
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates found in CA file %q", t.CAFile)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load client certificate")
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// validate validates the TLS configuration of the given key.
+func (t TLS) validate(key string) error {
+	if !t.Enabled {
+		return nil
+	}
+
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return errors.Errorf("%s: cert_file and key_file must be set together", key)
+	}
+
+	return validateFiles(key, t.CAFile, t.CertFile, t.KeyFile)
+}
+
+// validateFiles validates that all given (non-empty) files are accessible.
+func validateFiles(key string, paths ...string) error {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			return errors.Wrapf(err, "%s: unable to access file", key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// minSigningKeyLength holds the minimum key length (in bytes) of all
+// supported JWT signing methods. The key must be at least as long as the hash output.
+var minSigningKeyLength = map[string]int{
+	"HS256": 32,
+	"HS384": 48,
+	"HS512": 64,
+}
+
+// minBlobSigningKeyLength is the minimum length (in bytes) of the blob URL signing key,
+// which is used with HMAC-SHA256.
+const minBlobSigningKeyLength = 32
+
+// Validate validates the configuration.
+// All problems are reported at once, the returned error can be split
+// into the single problems with [multierr.Errors].
+func (c Config) Validate() error {
+	var err error
+
+	err = multierr.Append(err, validateListen("general.listen", c.General.Listen, false))
+	err = multierr.Append(err, validateListen("internal.listen", c.Internal.Listen, true))
+	err = multierr.Append(err, validateOneOf("general.environment", c.General.Environment, "development", "production"))
+	err = multierr.Append(err, validateOneOf("log.level", c.Log.Level, "debug", "info", "warn", "error", "fatal"))
+
+	if c.General.CompressionLevel > 2 {
+		err = multierr.Append(err, errors.Errorf("general.compression_level: must be between 0 and 2, got %d", c.General.CompressionLevel))
+	}
+
+	if len(c.General.CORS.AllowOrigins) == 0 {
+		err = multierr.Append(err, errors.New("general.cors.allow_origins: at least one origin is required"))
+	}
+
+	if c.General.RateLimit.Enabled && (c.General.RateLimit.Max <= 0 || c.General.RateLimit.Expiration <= 0) {
+		err = multierr.Append(err, errors.New("general.rate_limit: max and expiration must be greater than 0"))
+	}
+
+	err = multierr.Append(err, validateJWT(c))
+
+	if c.General.ShutdownDelay < 0 || c.General.ShutdownTimeout < 0 {
+		err = multierr.Append(err, errors.New("general.shutdown_delay/ general.shutdown_timeout: must not be negative"))
+	}
+
+	for name, rate := range c.Log.Access.SampleRates {
+		if rate < 0 || rate > 1 {
+			err = multierr.Append(err, errors.Errorf("log.access.sample_rates: rate of %q must be between 0 and 1", name))
+		}
+	}
+
+	err = multierr.Append(err, validateOneOf("error_reporting.reporter", c.ErrorReporting.Reporter, "none", "log", "sentry"))
+	if c.ErrorReporting.Reporter == "sentry" && c.ErrorReporting.DSN == "" {
+		err = multierr.Append(err, errors.New("error_reporting.dsn: must be set for the sentry reporter"))
+	}
+
+	err = multierr.Append(err, validateTracing(c))
+
+	if c.Metrics.Enabled && c.Metrics.StatsInterval <= 0 {
+		err = multierr.Append(err, errors.New("metrics.stats_interval: must be greater than 0"))
+	}
+
+	err = multierr.Append(err, validateDB(c))
+
+	err = multierr.Append(err, validateRedis(c))
+
+	err = multierr.Append(err, validateBlob(c))
+
+	return err
+}
+
+// validateBlob validates the blob storage configuration.
+func validateBlob(c Config) error {
+	var err error
+
+	b := c.Blob
+
+	err = multierr.Append(err, validateOneOf("blob.backend", b.Backend, "local", "s3"))
+
+	switch b.Backend {
+	case "local":
+		if b.Local.Path == "" {
+			err = multierr.Append(err, errors.New("blob.local.path: must be set for the local backend"))
+		}
+	case "s3":
+		if b.S3.Endpoint == "" || b.S3.Bucket == "" {
+			err = multierr.Append(err, errors.New("blob.s3.endpoint/ blob.s3.bucket: must be set for the s3 backend"))
+		}
+	}
+
+	if b.SigningKey != "" && len(b.SigningKey) < minBlobSigningKeyLength {
+		err = multierr.Append(err, errors.Errorf("blob.signing_key: must have at least %d bytes, got %d", minBlobSigningKeyLength, len(b.SigningKey)))
+	}
+
+	if b.URLTTL < time.Minute {
+		err = multierr.Append(err, errors.New("blob.url_ttl: must be at least 1m"))
+	}
+
+	if b.MaintenanceInterval < 0 || b.GCMinAge < 0 {
+		err = multierr.Append(err, errors.New("blob.maintenance_interval/ blob.gc_min_age: must not be negative"))
+	}
+
+	return err
+}
+
+// validateRedis validates the redis configuration.
+func validateRedis(c Config) error {
+	var err error
+
+	r := c.Redis
+
+	err = multierr.Append(err, validateOneOf("redis.mode", r.Mode, "standalone", "sentinel", "cluster"))
+	err = multierr.Append(err, validateOneOf("redis.network", r.Network, "tcp"))
+
+	if len(r.Address) == 0 {
+		err = multierr.Append(err, errors.New("redis.address: at least one address is required"))
+	}
+	for _, addr := range r.Address {
+		if _, _, e := net.SplitHostPort(strings.TrimSpace(addr)); e != nil {
+			err = multierr.Append(err, errors.Wrapf(e, "redis.address: invalid address %q", addr))
+		}
+	}
+
+	switch r.Mode {
+	case "standalone":
+		if len(r.Address) > 1 {
+			err = multierr.Append(err, errors.New("redis.address: only one address is allowed in standalone mode"))
+		}
+	case "sentinel":
+		if r.Sentinel.MasterSet == "" {
+			err = multierr.Append(err, errors.New("redis.sentinel.master_set: must be set in sentinel mode"))
+		}
+	case "cluster":
+		if r.DB != 0 {
+			err = multierr.Append(err, errors.New("redis.db: must be 0 in cluster mode"))
+		}
+	}
+
+	if r.DB < 0 {
+		err = multierr.Append(err, errors.New("redis.db: must not be negative"))
+	}
+
+	if r.DialTimeout <= 0 {
+		err = multierr.Append(err, errors.New("redis.dial_timeout: must be greater than 0"))
+	}
+
+	err = multierr.Append(err, r.TLS.validate("redis.tls"))
+
+	return err
+}
+
+// validateListen validates that the address is a valid "host:port" pair.
+func validateListen(key, addr string, optional bool) error {
+	if addr == "" {
+		if optional {
+			return nil
+		}
+
+		return errors.Errorf("%s: must be set", key)
+	}
+
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return errors.Errorf("%s: invalid address %q: %v", key, addr, err)
+	}
+
+	if p, err := strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+		return errors.Errorf("%s: invalid port %q", key, port)
+	}
+
+	return nil
+}
+
+// validateOneOf validates that the value is one of the allowed values.
+func validateOneOf(key, value string, allowed ...string) error {
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return nil
+		}
+	}
+
+	return errors.Errorf("%s: must be one of %s, got %q", key, strings.Join(allowed, ", "), value)
+}
+
+func validateJWT(c Config) error {
+	method := c.General.JWT.SigningMethod
+
+	minLen, ok := minSigningKeyLength[method]
+	if !ok {
+		methods := make([]string, 0, len(minSigningKeyLength))
+		for m := range minSigningKeyLength {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+
+		return errors.Errorf("general.jwt.signing_method: unsupported method %q, supported are %s", method, strings.Join(methods, ", "))
+	}
+
+	if key := c.General.JWT.SigningKey; key == "" {
+		return errors.New("general.jwt.signing_key: JWT signing key not provided")
+	} else if len(key) < minLen {
+		return errors.Errorf("general.jwt.signing_key: %s requires a key of at least %d bytes, got %d", method, minLen, len(key))
+	}
+
+	return nil
+}
+
+func validateTracing(c Config) error {
+	if !c.Debug.EnableTracing {
+		return nil
+	}
+
+	t := c.Debug.Tracing
+
+	err := validateOneOf("debug.tracing.exporter", t.Exporter, "otlp-grpc", "otlp-http", "stdout")
+
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		err = multierr.Append(err, errors.Errorf("debug.tracing.sample_ratio: must be between 0 and 1, got %g", t.SampleRatio))
+	}
+
+	for _, p := range t.Propagators {
+		err = multierr.Append(err, validateOneOf("debug.tracing.propagators", p, "tracecontext", "baggage", "b3", "b3multi"))
+	}
+
+	for _, attr := range t.ResourceAttributes {
+		if !strings.Contains(attr, "=") {
+			err = multierr.Append(err, errors.Errorf("debug.tracing.resource_attributes: %q is not in the key=value format", attr))
+		}
+	}
+
+	return err
+}
+
+// validateDB validates the database configuration.
+func validateDB(c Config) error {
+	err := validateDBURI(c.DB.URI)
+
+	t := c.DB.TLS
+	if t.Mode != "" {
+		err = multierr.Append(err, validateOneOf("db.tls.mode", t.Mode, "disable", "require", "verify-ca", "verify-full"))
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		err = multierr.Append(err, errors.New("db.tls: cert_file and key_file must be set together"))
+	}
+	if t.Mode == "" && (t.CAFile != "" || t.CertFile != "") {
+		err = multierr.Append(err, errors.New("db.tls.mode: must be set if certificate files are configured"))
+	}
+	err = multierr.Append(err, validateFiles("db.tls", t.CAFile, t.CertFile, t.KeyFile))
+
+	p := c.DB.Pool
+	if p.MaxOpenConns < 0 || p.MaxIdleConns < 0 || p.ConnMaxLifetime < 0 || p.ConnMaxIdleTime < 0 {
+		err = multierr.Append(err, errors.New("db.pool: values must not be negative"))
+	}
+	if p.MaxOpenConns > 0 && p.MaxIdleConns > p.MaxOpenConns {
+		err = multierr.Append(err, errors.New("db.pool.max_idle_conns: must not be greater than max_open_conns"))
+	}
+
+	if c.DB.DialTimeout <= 0 || c.DB.ReadTimeout <= 0 || c.DB.StartupTimeout <= 0 {
+		err = multierr.Append(err, errors.New("db.dial_timeout/ db.read_timeout/ db.startup_timeout: must be greater than 0"))
+	}
+	if c.DB.StatementTimeout < 0 || c.DB.LockTimeout < 0 {
+		err = multierr.Append(err, errors.New("db.statement_timeout/ db.lock_timeout: must not be negative"))
+	}
+	if c.DB.StatementTimeout > 0 && c.DB.StatementTimeout >= c.DB.ReadTimeout {
+		err = multierr.Append(err, errors.New("db.read_timeout: must be greater than db.statement_timeout"))
+	}
+
+	return err
+}
+
+// validateDBURI validates the database connection URI.
+func validateDBURI(uri string) error {
+	if uri == "" {
+		return errors.New("db.uri: must be set")
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		// NOTE: The error contains the URI, which may contain the password.
+		return errors.New("db.uri: unable to parse URI")
+	}
+
+	if u.Scheme == "sqlite" {
+		if u.Opaque == "" && u.Path == "" {
+			return errors.New("db.uri: database path must be set, i.e. sqlite:/var/lib/briefkasten/briefkasten.db")
+		}
+
+		return nil
+	}
+
+	if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+		return errors.Errorf("db.uri: unsupported scheme %q", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return errors.New("db.uri: host must be set")
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+// Package export creates the data archive of a user.
+//
+// The archive is a zip file containing one JSON document per record type,
+// so that users can download all of their personal data.
+// The referenced blobs, e.g. the avatar, are stored in the "blobs/" directory,
+// named after the key of their "blob:<key>" reference.
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// Bookmark is an exported bookmark.
+type Bookmark struct {
+	models.Bookmark
+
+	TagIDs []uuid.UUID `json:"tag_ids"`
+}
+
+// Session is an exported session.
+// The token and provider data are credentials and therefore not exported.
+type Session struct {
+	Expires time.Time `json:"expires"`
+}
+
+// Archive holds all data of a user.
+type Archive struct {
+	UserAccount models.UserAccount
+	Categories  []models.Category
+	Tags        []models.Tag
+	Bookmarks   []Bookmark
+	Sessions    []Session
+}
+
+// Collect retrieves all data of the user in one transaction.
+func Collect(ctx context.Context, s store.Store, userID uuid.UUID) (Archive, error) {
+	var a Archive
+
+	err := s.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		if a.UserAccount, err = s.Users.Get(ctx, userID); err != nil {
+			return err
+		}
+
+		a.Categories, err = listAll(func(p store.Page) ([]models.Category, error) {
+			return s.Categories.List(ctx, userID, p)
+		}, func(c models.Category) (uuid.UUID, time.Time) { return c.ID, c.CreatedAt })
+		if err != nil {
+			return err
+		}
+
+		a.Tags, err = listAll(func(p store.Page) ([]models.Tag, error) {
+			return s.Tags.List(ctx, userID, p)
+		}, func(t models.Tag) (uuid.UUID, time.Time) { return t.ID, t.CreatedAt })
+		if err != nil {
+			return err
+		}
+
+		bookmarks, err := listAll(func(p store.Page) ([]models.Bookmark, error) {
+			return s.Bookmarks.List(ctx, userID, p)
+		}, func(b models.Bookmark) (uuid.UUID, time.Time) { return b.ID, b.CreatedAt })
+		if err != nil {
+			return err
+		}
+
+		a.Bookmarks = make([]Bookmark, len(bookmarks))
+		for i, b := range bookmarks {
+			a.Bookmarks[i].Bookmark = b
+
+			if a.Bookmarks[i].TagIDs, err = s.Bookmarks.TagIDs(ctx, b.ID); err != nil {
+				return err
+			}
+		}
+
+		sessions, err := s.Users.Sessions(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		a.Sessions = make([]Session, len(sessions))
+		for i, ses := range sessions {
+			a.Sessions[i] = Session{Expires: ses.Expires}
+		}
+
+		return nil
+	})
+
+	return a, errors.Wrap(err, "unable to collect user data")
+}
+
+// BlobKeys returns the keys of all blobs referenced by the archive.
+func (a Archive) BlobKeys() []string {
+	images := []null.String{a.UserAccount.Image}
+	for _, b := range a.Bookmarks {
+		images = append(images, b.Image)
+	}
+
+	var keys []string
+	seen := make(map[string]struct{})
+
+	for _, img := range images {
+		key, ok := blob.KeyOf(img.String)
+		if !img.Valid || !ok {
+			continue
+		}
+
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// WriteZip writes the archive including the referenced blobs as zip file to w.
+// Blobs, which do not exist anymore, are skipped.
+func (a Archive) WriteZip(ctx context.Context, w io.Writer, blobs blob.Store) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"user_account.json", a.UserAccount},
+		{"categories.json", a.Categories},
+		{"tags.json", a.Tags},
+		{"bookmarks.json", a.Bookmarks},
+		{"sessions.json", a.Sessions},
+	}
+
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return errors.Wrapf(err, "unable to create %s", f.name)
+		}
+
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(f.data); err != nil {
+			return errors.Wrapf(err, "unable to encode %s", f.name)
+		}
+	}
+
+	for _, key := range a.BlobKeys() {
+		if err := writeBlob(ctx, zw, blobs, key); err != nil {
+			return err
+		}
+	}
+
+	return errors.Wrap(zw.Close(), "unable to finish zip archive")
+}
+
+// writeBlob copies the blob into the "blobs/" directory of the archive.
+func writeBlob(ctx context.Context, zw *zip.Writer, blobs blob.Store, key string) error {
+	r, err := blobs.Get(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	// blobs are already compressed images
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: "blobs/" + key, Method: zip.Store})
+	if err != nil {
+		return errors.Wrapf(err, "unable to create blob %s", key)
+	}
+
+	_, err = io.Copy(fw, r)
+
+	return errors.Wrapf(err, "unable to copy blob %s", key)
+}
+
+// listAll retrieves all pages of a list.
+func listAll[T any](list func(store.Page) ([]T, error), key func(T) (uuid.UUID, time.Time)) ([]T, error) {
+	ret := []T{}
+
+	var page store.Page
+
+	for {
+		records, err := list(page)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, records...)
+
+		if len(records) < page.Size() {
+			return ret, nil
+		}
+
+		page.AfterID, page.AfterTime = key(records[len(records)-1])
+	}
+}
@@ -0,0 +1,184 @@
+// Package images moves the images of the records into the blob storage
+// and deletes the blobs, which are no longer referenced.
+package images
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/pkg/helper"
+)
+
+const (
+	// MaxSize is the maximum size of an imported image in bytes.
+	MaxSize = 5 << 20
+	// fetchTimeout is the timeout for downloading a single image.
+	fetchTimeout = 10 * time.Second
+)
+
+// publicClient is the client used to download images from user provided URLs.
+var publicClient = helper.NewPublicClient()
+
+// client returns the HTTP client used to download images.
+func client() *http.Client {
+	if config.C.Load().Blob.AllowPrivateNetworks {
+		return http.DefaultClient
+	}
+
+	return publicClient
+}
+
+// ErrNotImage is returned if the downloaded data is no supported image.
+var ErrNotImage = errors.New("unsupported image type")
+
+// ImportResult is the result of an import.
+type ImportResult struct {
+	// Imported is the number of bookmarks, whose image has been imported.
+	Imported int `json:"imported"`
+	// Failed is the number of bookmarks, whose image could not be downloaded.
+	// They are retried by the next import.
+	Failed int `json:"failed"`
+}
+
+// Download downloads the image from the URL and returns its data and extension.
+//
+// Only public addresses are allowed, unless blob.allow_private_networks is enabled.
+func Download(ctx context.Context, url string, maxSize int64) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	data, err := helper.Download(ctx, client(), url, maxSize)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "unable to download image")
+	}
+
+	ext, ok := blob.ImageExt(data)
+	if !ok {
+		return nil, "", ErrNotImage
+	}
+
+	return data, ext, nil
+}
+
+// Import downloads the image and stores it as blob.
+// It returns the reference to the blob.
+func Import(ctx context.Context, blobs blob.Store, url string) (string, error) {
+	data, ext, err := Download(ctx, url, MaxSize)
+	if err != nil {
+		return "", err
+	}
+
+	key := blob.Key(data, ext)
+	if err := blobs.Put(ctx, key, data); err != nil {
+		return "", err
+	}
+
+	return blob.Ref(key), nil
+}
+
+// ImportBookmarks imports the remote images of all bookmarks into the blob storage.
+func ImportBookmarks(ctx context.Context, s store.Store, blobs blob.Store) (ImportResult, error) {
+	var (
+		res  ImportResult
+		page store.Page
+	)
+
+	for {
+		bookmarks, err := s.Bookmarks.ListRemoteImages(ctx, page)
+		if err != nil {
+			return res, err
+		}
+
+		for _, b := range bookmarks {
+			ref, err := Import(ctx, blobs, b.Image.String)
+			if err != nil {
+				if ctx.Err() != nil {
+					return res, ctx.Err()
+				}
+
+				log.Debug("Unable to import bookmark image", zap.Error(err), zap.Stringer("bookmark_id", b.ID))
+				res.Failed++
+
+				continue
+			}
+
+			// the image may have been changed while it has been downloaded
+			err = s.Bookmarks.ReplaceImage(ctx, b.ID, b.Image.String, ref)
+			if err != nil && !errors.Is(err, store.ErrNotFound) {
+				return res, err
+			}
+
+			if err == nil {
+				res.Imported++
+			}
+		}
+
+		if len(bookmarks) < page.Size() {
+			return res, nil
+		}
+
+		last := bookmarks[len(bookmarks)-1]
+		page.AfterID, page.AfterTime = last.ID, last.CreatedAt
+	}
+}
+
+// Referenced returns the keys of all blobs referenced by the users and bookmarks.
+func Referenced(ctx context.Context, s store.Store) (map[string]struct{}, error) {
+	keys := make(map[string]struct{})
+
+	for _, list := range []func(context.Context) ([]string, error){s.Users.BlobRefs, s.Bookmarks.BlobRefs} {
+		refs, err := list(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ref := range refs {
+			if key, ok := blob.KeyOf(ref); ok {
+				keys[key] = struct{}{}
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// GC deletes all unreferenced blobs older than minAge, see [blob.GC].
+func GC(ctx context.Context, s store.Store, blobs blob.Store, minAge time.Duration, dryRun bool) (blob.GCResult, error) {
+	referenced, err := Referenced(ctx, s)
+	if err != nil {
+		return blob.GCResult{}, err
+	}
+
+	return blob.GC(ctx, blobs, referenced, minAge, dryRun)
+}
+
+// Maintain imports the remote bookmark images and deletes the unreferenced blobs.
+func Maintain(ctx context.Context, s store.Store, blobs blob.Store, gcMinAge time.Duration) error {
+	imported, err := ImportBookmarks(ctx, s, blobs)
+	if err != nil {
+		return errors.Wrap(err, "unable to import bookmark images")
+	}
+
+	gc, err := GC(ctx, s, blobs, gcMinAge, false)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Blob maintenance finished",
+		zap.Int("imported", imported.Imported),
+		zap.Int("import_failed", imported.Failed),
+		zap.Int("blobs", gc.Scanned),
+		zap.Int("deleted", gc.Deleted),
+		zap.Int64("freed_bytes", gc.Freed),
+	)
+
+	return nil
+}
@@ -14,8 +14,6 @@ var (
 	zapLvl zap.AtomicLevel
 )
 
-// var sentryClient *sentry.Client
-
 // InitLogging initializes the logger.
 // If the initialization sequence fails, the function will call os.Exit(1).
 func InitLogging(logLvl, logEnv string) {
@@ -51,10 +49,44 @@ func InitLogging(logLvl, logEnv string) {
 	}
 }
 
+// NewAccessLogger returns a logger for HTTP access logs.
+// It uses the same configuration as the application logger, but without
+// sampling, because every access log entry has the same message.
+//
+// The logger always logs at InfoLevel, independent of the configured and
+// runtime log level. The access log is enabled by log.access.enabled instead.
+func NewAccessLogger() *zap.Logger {
+	cfg := logCfg
+	cfg.Level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	cfg.Sampling = nil
+	cfg.DisableCaller = true
+	cfg.DisableStacktrace = true
+
+	l, err := cfg.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return l.Named("access")
+}
+
 // SetLevel allows to set a new log level.
-func SetLevel(newLevel string) {
-	lvl := decodeLogLevel(newLevel)
+// The previous log level is returned.
+func SetLevel(newLevel string) (string, error) {
+	lvl, err := parseLogLevel(newLevel)
+	if err != nil {
+		return "", err
+	}
+
+	old := zapLvl.Level()
 	zapLvl.SetLevel(lvl)
+
+	return old.String(), nil
+}
+
+// Level returns the current log level.
+func Level() string {
+	return zapLvl.Level().String()
 }
 
 // newProductionConfig is a reasonable production logging configuration.
@@ -95,24 +127,33 @@ func newDevelopmentConfig(lvl zap.AtomicLevel) zap.Config {
 }
 
 // decodeLogLevel decodes a "log level string" into the zap.AtomicLevel.
+// If the level is unknown, the function will call os.Exit(1).
 func decodeLogLevel(l string) zapcore.Level {
+	lvl, err := parseLogLevel(l)
+	if err != nil {
+		fmt.Println("Unknown log level provided")
+		os.Exit(1)
+	}
+
+	return lvl
+}
+
+// parseLogLevel parses a "log level string" into the zapcore.Level.
+func parseLogLevel(l string) (zapcore.Level, error) {
 	switch strings.ToLower(l) {
 	case "debug":
-		return zapcore.DebugLevel
+		return zapcore.DebugLevel, nil
 	case "info":
-		return zapcore.InfoLevel
+		return zapcore.InfoLevel, nil
 	case "warn":
-		return zapcore.WarnLevel
+		return zapcore.WarnLevel, nil
 	case "error":
-		return zapcore.ErrorLevel
+		return zapcore.ErrorLevel, nil
 	case "fatal":
-		return zapcore.FatalLevel
+		return zapcore.FatalLevel, nil
 	default:
-		fmt.Println("Unknown log level provided")
-		os.Exit(1)
+		return zapcore.ErrorLevel, fmt.Errorf("unknown log level %q", l)
 	}
-
-	return zapcore.ErrorLevel
 }
 
 // logger is the logger instance.
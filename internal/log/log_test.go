@@ -0,0 +1,31 @@
+package log_test
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+)
+
+func TestAccessLoggerLevel(t *testing.T) {
+	log.InitLogging("error", "production")
+
+	l := log.NewAccessLogger()
+
+	if !l.Core().Enabled(zapcore.InfoLevel) {
+		t.Error("expected the access logger to log at info level, although the log level is error")
+	}
+
+	if _, err := log.SetLevel("fatal"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !l.Core().Enabled(zapcore.InfoLevel) {
+		t.Error("expected the access logger to log at info level after changing the log level")
+	}
+
+	if l.Core().Enabled(zapcore.DebugLevel) {
+		t.Error("expected the access logger not to log at debug level")
+	}
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// QueryHook is a [bun.QueryHook] recording the duration of all queries.
+type QueryHook struct{}
+
+var _ bun.QueryHook = (*QueryHook)(nil)
+
+// NewQueryHook returns a new [QueryHook].
+func NewQueryHook() *QueryHook {
+	return &QueryHook{}
+}
+
+// BeforeQuery implements [bun.QueryHook].
+func (h *QueryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+// AfterQuery implements [bun.QueryHook].
+func (h *QueryHook) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	dbQueryDuration.WithLabelValues(event.Operation()).
+		Observe(time.Since(event.StartTime).Seconds())
+}
@@ -0,0 +1,15 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+// ObserveHTTPRequest records a handled HTTP request.
+//
+// The route must be the route pattern (i.e. "/users/:id/tags") and not the
+// requested path, to keep the cardinality of the metric bounded.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequestsTotal.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace is the prefix of all metric names.
+const namespace = "briefkasten"
+
+// registry holds all metrics exposed by the application.
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of handled HTTP requests.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of handled HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of database queries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	redisCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "command_duration_seconds",
+		Help:      "Duration of redis commands, pipelines are recorded as a whole with the command PIPELINE.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"command"})
+
+	redisCacheResultsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "client_cache_results_total",
+		Help:      "Total number of client side cache lookups, partitioned by hit and miss.",
+	}, []string{"result"})
+
+	usersGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "users",
+		Help:      "Number of registered users.",
+	})
+
+	bookmarksGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "bookmarks",
+		Help:      "Number of stored bookmarks.",
+	})
+
+	tagsGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tags",
+		Help:      "Number of stored tags.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		dbQueryDuration,
+		redisCommandDuration,
+		redisCacheResultsTotal,
+		usersGauge,
+		bookmarksGauge,
+		tagsGauge,
+	)
+}
+
+// Handler returns the HTTP handler exposing all metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		Registry: registry,
+	})
+}
+
+// Stats holds the business statistics.
+type Stats struct {
+	Users     int
+	Bookmarks int
+	Tags      int
+}
+
+// SetStats updates the business statistic gauges.
+func SetStats(s Stats) {
+	usersGauge.Set(float64(s.Users))
+	bookmarksGauge.Set(float64(s.Bookmarks))
+	tagsGauge.Set(float64(s.Tags))
+}
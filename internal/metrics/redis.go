@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/rueian/rueidis"
+	"github.com/rueian/rueidis/rueidishook"
+)
+
+// pipelineCommand is the command label of pipelines.
+// The commands of a pipeline are sent together, their latencies
+// can therefore not be measured individually.
+const pipelineCommand = "PIPELINE"
+
+// RedisHook is a [rueidishook.Hook] recording the latency of all redis commands
+// and the hit/ miss ratio of the client side cache.
+// A pipeline is recorded as a single observation, see [pipelineCommand].
+type RedisHook struct{}
+
+var _ rueidishook.Hook = (*RedisHook)(nil)
+
+// Do implements [rueidishook.Hook].
+func (RedisHook) Do(client rueidis.Client, ctx context.Context, cmd rueidishook.Completed) rueidis.RedisResult {
+	// NOTE: The command is recycled by the client, so the name must be resolved in advance.
+	name := commandName(cmd.Commands())
+	start := time.Now()
+
+	resp := client.Do(ctx, cmd)
+	observeRedisCommand(name, start)
+
+	return resp
+}
+
+// DoMulti implements [rueidishook.Hook].
+func (RedisHook) DoMulti(client rueidis.Client, ctx context.Context, multi ...rueidishook.Completed) []rueidis.RedisResult {
+	start := time.Now()
+
+	resps := client.DoMulti(ctx, multi...)
+	observeRedisCommand(pipelineCommand, start)
+
+	return resps
+}
+
+// DoCache implements [rueidishook.Hook].
+func (RedisHook) DoCache(client rueidis.Client, ctx context.Context, cmd rueidishook.Cacheable, ttl time.Duration) rueidis.RedisResult {
+	name := commandName(cmd.Commands())
+	start := time.Now()
+
+	resp := client.DoCache(ctx, cmd, ttl)
+	observeRedisCommand(name, start)
+	observeCacheResult(resp)
+
+	return resp
+}
+
+// DoMultiCache implements [rueidishook.Hook].
+func (RedisHook) DoMultiCache(client rueidis.Client, ctx context.Context, multi ...rueidis.CacheableTTL) []rueidis.RedisResult {
+	start := time.Now()
+
+	resps := client.DoMultiCache(ctx, multi...)
+	observeRedisCommand(pipelineCommand, start)
+
+	for _, resp := range resps {
+		observeCacheResult(resp)
+	}
+
+	return resps
+}
+
+// Receive implements [rueidishook.Hook].
+func (RedisHook) Receive(client rueidis.Client, ctx context.Context, subscribe rueidishook.Completed, fn func(msg rueidis.PubSubMessage)) error {
+	return client.Receive(ctx, subscribe, fn)
+}
+
+func observeRedisCommand(name string, start time.Time) {
+	redisCommandDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+}
+
+func observeCacheResult(resp rueidis.RedisResult) {
+	result := "miss"
+	if resp.IsCacheHit() {
+		result = "hit"
+	}
+
+	redisCacheResultsTotal.WithLabelValues(result).Inc()
+}
+
+// commandName returns the name of the redis command, i.e. "GET".
+func commandName(cmd []string) string {
+	if len(cmd) == 0 {
+		return "unknown"
+	}
+
+	return cmd[0]
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/rueian/rueidis"
+	"github.com/rueian/rueidis/rueidishook"
+)
+
+func TestRedisHookPipeline(t *testing.T) {
+	client, err := rueidis.NewClient(rueidis.ClientOption{
+		InitAddress: []string{miniredis.RunT(t).Addr()},
+		// miniredis does not support client-side caching
+		DisableCache: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	rdb := rueidishook.WithHook(client, RedisHook{})
+	ctx := context.Background()
+
+	redisCommandDuration.Reset()
+
+	if err := rdb.Do(ctx, rdb.B().Set().Key("a").Value("1").Build()).Error(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, resp := range rdb.DoMulti(ctx,
+		rdb.B().Set().Key("b").Value("2").Build(),
+		rdb.B().Get().Key("a").Build(),
+		rdb.B().Get().Key("b").Build(),
+	) {
+		if err := resp.Error(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+
+	// the pipeline is observed once, not once per command
+	for _, want := range []string{
+		`briefkasten_redis_command_duration_seconds_count{command="SET"} 1`,
+		`briefkasten_redis_command_duration_seconds_count{command="PIPELINE"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metric %s, got:\n%s", want, body)
+		}
+	}
+
+	if strings.Contains(body, `command="GET"`) {
+		t.Errorf("expected no observation of the pipelined commands, got:\n%s", body)
+	}
+}
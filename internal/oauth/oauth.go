@@ -4,6 +4,7 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/amazon"
@@ -36,6 +37,7 @@ import (
 	"github.com/markbates/goth/providers/spotify"
 	"github.com/markbates/goth/providers/twitch"
 	"github.com/markbates/goth/providers/yammer"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
@@ -90,12 +92,36 @@ func Init() {
 	}
 }
 
+// GetProvider returns the provider with the given name.
+// An error is returned if the provider is unknown or not enabled
+// in the current configuration.
+func GetProvider(name string) (goth.Provider, error) {
+	enabled := config.C.Load().OAuth2.Providers
+	if len(enabled) > 0 && !contains(enabled, name) {
+		return nil, errors.Errorf("provider %q is not enabled", name)
+	}
+
+	prov, err := goth.GetProvider(name)
+
+	return prov, errors.Wrap(err, "unknown provider")
+}
+
 // genCallbackURL returns a valid callback url for the provider.
 func genCallbackURL(provider string) string {
-	u, err := url.JoinPath(config.C.OAuth2.Endpoint, "/api/v1/auth/", provider, "/callback")
+	u, err := url.JoinPath(config.C.Load().OAuth2.Endpoint, "/api/v1/auth/", provider, "/callback")
 	if err != nil {
 		log.Fatal("Unable to generate provider URL", zap.Error(err))
 	}
 
 	return u
 }
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,6 +2,8 @@ package redis
 
 import (
 	"encoding/json"
+
+	"github.com/google/uuid"
 )
 
 // Key represents a cache key prefix.
@@ -16,9 +18,24 @@ const (
 	// cacheKeySessionID is the session-id cache key prefix.
 	// It resolves a session ID to a user.
 	cacheKeySessionID = Key("session:")
+
+	// cacheKeyUser is the prefix of all cache keys of a user.
+	// It is followed by the user ID and another colon.
+	cacheKeyUser = Key("user:")
 )
 
 // SessionID returns the cache [Key] for User ID lookups.
 func SessionID(id string) Key {
 	return cacheKeySessionID + Key(id)
 }
+
+// User returns the cache [Key] prefix of all data cached for the user.
+func User(id uuid.UUID) Key {
+	return cacheKeyUser + Key(id.String()) + ":"
+}
+
+// UserSessions returns the cache [Key] of the set of all session IDs of the user.
+// It indexes the sessions, which are deleted with the user, see [PurgeUser].
+func UserSessions(id uuid.UUID) Key {
+	return User(id) + "sessions"
+}
@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+	"github.com/rueian/rueidis"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+// NewClientOption returns the client options of the redis configuration.
+//
+// In standalone and cluster mode, rueidis detects the cluster based on the
+// addresses. In sentinel mode, the addresses are the ones of the sentinels
+// and the master is resolved using the master set name.
+func NewClientOption(cfg *config.Config) (rueidis.ClientOption, error) {
+	r := cfg.Redis
+
+	tlsCfg, err := r.TLS.Config()
+	if err != nil {
+		return rueidis.ClientOption{}, errors.Wrap(err, "invalid redis TLS configuration")
+	}
+
+	opt := rueidis.ClientOption{
+		InitAddress: r.Address,
+		Username:    r.Username,
+		Password:    r.Password,
+		SelectDB:    r.DB,
+		Dialer:      net.Dialer{Timeout: r.DialTimeout},
+		TLSConfig:   tlsCfg,
+	}
+
+	if r.Mode == "sentinel" {
+		opt.Sentinel = rueidis.SentinelOption{
+			Dialer:    net.Dialer{Timeout: r.DialTimeout},
+			TLSConfig: tlsCfg,
+			MasterSet: r.Sentinel.MasterSet,
+			Username:  r.Sentinel.Username,
+			Password:  r.Sentinel.Password,
+		}
+	}
+
+	return opt, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/google/uuid"
 	"github.com/markbates/goth"
 	"github.com/pkg/errors"
 	"github.com/rueian/rueidis"
@@ -61,10 +62,36 @@ func (u *UserSession) UnmarshalBinary(data []byte) error {
 }
 
 // StoreUserSession stores the user session.
-func StoreUserSession(ctx context.Context, sesID string, ses UserSession) error {
+//
+// If the user is known, the session is indexed for the user,
+// so that it is deleted with the user (see [PurgeUser]).
+// uuid.Nil is used for sessions of a pending login.
+func StoreUserSession(ctx context.Context, userID uuid.UUID, sesID string, ses UserSession) error {
 	const sessionTTL = 24 * time.Hour
 
-	return ESetTTL(ctx, SessionID(sesID), ses, sessionTTL)
+	if err := ESetTTL(ctx, SessionID(sesID), ses, sessionTTL); err != nil {
+		return err
+	}
+
+	if userID == uuid.Nil {
+		return nil
+	}
+
+	index := string(UserSessions(userID))
+
+	// the index expires with the last stored session
+	cmds := rueidis.Commands{
+		c.B().Sadd().Key(index).Member(sesID).Build(),
+		c.B().Expire().Key(index).Seconds(int64(sessionTTL.Seconds())).Build(),
+	}
+
+	for _, resp := range c.DoMulti(ctx, cmds...) {
+		if err := resp.Error(); err != nil {
+			return errors.Wrap(err, "unable to index user session")
+		}
+	}
+
+	return nil
 }
 
 // GetUserSession returns the stored user session.
@@ -80,7 +107,11 @@ func GetUserSession(ctx context.Context, sesID string, provider goth.Provider) (
 		Prov: provider,
 	}
 
-	err := c.DoCache(ctx, cmd, ttl).DecodeJSON(&u)
+	// NOTE: The session must be decoded by the provider, see [UserSession.UnmarshalBinary].
+	data, err := c.DoCache(ctx, cmd, ttl).ToString()
+	if err == nil {
+		err = u.UnmarshalBinary([]byte(data))
+	}
 	if err != nil {
 		if !rueidis.IsRedisNil(err) {
 			log.Error("Unable to retrieve user session from redis", zap.Error(err))
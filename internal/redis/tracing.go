@@ -0,0 +1,190 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/rueian/rueidis"
+	"github.com/rueian/rueidis/rueidishook"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/fabmation-gmbh/briefkasten-go/internal/redis")
+
+// TracingHook is a [rueidishook.Hook] creating a span for every redis command.
+// The spans are children of the span in the context passed to the command.
+//
+// NOTE: Only the key prefix (i.e. "session:") is recorded, because the keys
+// may contain sensitive information like session IDs.
+type TracingHook struct{}
+
+var _ rueidishook.Hook = (*TracingHook)(nil)
+
+// Do implements [rueidishook.Hook].
+func (TracingHook) Do(client rueidis.Client, ctx context.Context, cmd rueidishook.Completed) rueidis.RedisResult {
+	ctx, span := startSpan(ctx, cmd.Commands())
+	defer span.End()
+
+	resp := client.Do(ctx, cmd)
+	recordError(span, resp.Error())
+
+	return resp
+}
+
+// DoMulti implements [rueidishook.Hook].
+func (TracingHook) DoMulti(client rueidis.Client, ctx context.Context, multi ...rueidishook.Completed) []rueidis.RedisResult {
+	cmds := make([][]string, len(multi))
+	for i := range multi {
+		cmds[i] = multi[i].Commands()
+	}
+
+	ctx, span := startPipelineSpan(ctx, cmds)
+	defer span.End()
+
+	resps := client.DoMulti(ctx, multi...)
+	recordError(span, firstError(resps))
+
+	return resps
+}
+
+// DoCache implements [rueidishook.Hook].
+func (TracingHook) DoCache(client rueidis.Client, ctx context.Context, cmd rueidishook.Cacheable, ttl time.Duration) rueidis.RedisResult {
+	ctx, span := startSpan(ctx, cmd.Commands())
+	defer span.End()
+
+	resp := client.DoCache(ctx, cmd, ttl)
+	span.SetAttributes(attribute.Bool("redis.cache_hit", resp.IsCacheHit()))
+	recordError(span, resp.Error())
+
+	return resp
+}
+
+// DoMultiCache implements [rueidishook.Hook].
+func (TracingHook) DoMultiCache(client rueidis.Client, ctx context.Context, multi ...rueidis.CacheableTTL) []rueidis.RedisResult {
+	cmds := make([][]string, len(multi))
+	for i := range multi {
+		cmds[i] = multi[i].Cmd.Commands()
+	}
+
+	ctx, span := startPipelineSpan(ctx, cmds)
+	defer span.End()
+
+	resps := client.DoMultiCache(ctx, multi...)
+
+	hits := 0
+	for _, resp := range resps {
+		if resp.IsCacheHit() {
+			hits++
+		}
+	}
+
+	span.SetAttributes(attribute.Int("redis.cache_hits", hits))
+	recordError(span, firstError(resps))
+
+	return resps
+}
+
+// Receive implements [rueidishook.Hook].
+func (TracingHook) Receive(client rueidis.Client, ctx context.Context, subscribe rueidishook.Completed, fn func(msg rueidis.PubSubMessage)) error {
+	ctx, span := startSpan(ctx, subscribe.Commands())
+	defer span.End()
+
+	err := client.Receive(ctx, subscribe, fn)
+	recordError(span, err)
+
+	return err
+}
+
+// startSpan starts the span of a single command.
+func startSpan(ctx context.Context, cmd []string) (context.Context, trace.Span) {
+	name := commandName(cmd)
+
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemRedis,
+		semconv.DBOperationKey.String(name),
+	}
+
+	if prefix := keyPrefix(cmd); prefix != "" {
+		attrs = append(attrs, attribute.String("redis.key_prefix", prefix))
+	}
+
+	return tracer.Start(ctx, "redis."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// startPipelineSpan starts the span of multiple pipelined commands.
+func startPipelineSpan(ctx context.Context, cmds [][]string) (context.Context, trace.Span) {
+	names := make([]string, len(cmds))
+	prefixes := make([]string, 0, len(cmds))
+
+	for i, cmd := range cmds {
+		names[i] = commandName(cmd)
+
+		if prefix := keyPrefix(cmd); prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	return tracer.Start(ctx, "redis.pipeline",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemRedis,
+			semconv.DBOperationKey.String("pipeline"),
+			attribute.StringSlice("redis.commands", names),
+			attribute.StringSlice("redis.key_prefixes", prefixes),
+		),
+	)
+}
+
+// recordError records the error on the span.
+// A nil reply is not considered an error.
+func recordError(span trace.Span, err error) {
+	if err == nil || rueidis.IsRedisNil(err) {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+func firstError(resps []rueidis.RedisResult) error {
+	for _, resp := range resps {
+		if err := resp.Error(); err != nil && !rueidis.IsRedisNil(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commandName returns the name of the redis command, i.e. "GET".
+func commandName(cmd []string) string {
+	if len(cmd) == 0 {
+		return "UNKNOWN"
+	}
+
+	return cmd[0]
+}
+
+// keyPrefix returns the prefix of the key the command operates on, i.e. "session:".
+// All keys of this application are prefixed with a [Key] ending with a colon.
+// If the command has no prefixed key, an empty string is returned.
+func keyPrefix(cmd []string) string {
+	if len(cmd) < 2 {
+		return ""
+	}
+
+	i := strings.IndexByte(cmd[1], ':')
+	if i < 0 {
+		return ""
+	}
+
+	return cmd[1][:i+1]
+}
@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rueian/rueidis"
+	"go.uber.org/zap"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+)
+
+// PurgeUser deletes all sessions of the user indexed by [StoreUserSession].
+//
+// Every key is deleted with its own command, since the keys of the sessions
+// are stored in different hash slots in cluster mode.
+func PurgeUser(ctx context.Context, id uuid.UUID) error {
+	index := string(UserSessions(id))
+
+	sessions, err := c.Do(ctx, c.B().Smembers().Key(index).Build()).AsStrSlice()
+	if err != nil && !rueidis.IsRedisNil(err) {
+		return errors.Wrap(err, "unable to retrieve user sessions")
+	}
+
+	cmds := make(rueidis.Commands, 0, len(sessions))
+	for _, sesID := range sessions {
+		cmds = append(cmds, c.B().Del().Key(string(SessionID(sesID))).Build())
+	}
+
+	for _, resp := range c.DoMulti(ctx, cmds...) {
+		if err := resp.Error(); err != nil {
+			return errors.Wrap(err, "unable to delete user sessions")
+		}
+	}
+
+	// the index is deleted last, so that a failed purge can be retried
+	if err := c.Do(ctx, c.B().Del().Key(index).Build()).Error(); err != nil {
+		return errors.Wrap(err, "unable to delete user session index")
+	}
+
+	log.Debug("Purged user sessions", zap.Stringer("user_id", id), zap.Int("sessions", len(sessions)))
+
+	return nil
+}
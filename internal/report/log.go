@@ -0,0 +1,36 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+)
+
+// LogReporter is a [Reporter] writing all events to the log.
+type LogReporter struct{}
+
+var _ Reporter = (*LogReporter)(nil)
+
+// NewLogReporter returns a new [LogReporter].
+func NewLogReporter() *LogReporter {
+	return &LogReporter{}
+}
+
+// Report implements [Reporter].
+func (r *LogReporter) Report(_ context.Context, e Event) {
+	log.Error("Error reported",
+		zap.Error(e.Err),
+		zap.Time("timestamp", e.Timestamp),
+		zap.String("request_id", e.RequestID),
+		zap.String("method", e.Method),
+		zap.String("route", e.Route),
+		zap.String("user_id", e.UserID),
+		zap.String("stack", e.Stack),
+	)
+}
+
+// Flush implements [Reporter].
+func (r *LogReporter) Flush(time.Duration) {}
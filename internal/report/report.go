@@ -0,0 +1,94 @@
+package report
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+// Supported reporters.
+const (
+	ReporterNone   = "none"
+	ReporterLog    = "log"
+	ReporterSentry = "sentry"
+)
+
+// Event describes a reported error.
+type Event struct {
+	// Err is the reported error.
+	Err error
+	// Timestamp is the time the error occurred.
+	Timestamp time.Time
+	// RequestID is the ID of the request, if any.
+	RequestID string
+	// Method is the HTTP method of the request, if any.
+	Method string
+	// Route is the matched route of the request, if any.
+	Route string
+	// UserID is the ID of the authenticated user, if any.
+	UserID string
+	// Stack is the stack trace of the error, if known.
+	Stack string
+}
+
+// Reporter reports errors to an error tracking system.
+type Reporter interface {
+	// Report reports the event.
+	// It must not block the caller.
+	Report(ctx context.Context, e Event)
+	// Flush waits until all pending events have been reported,
+	// or the timeout has been reached.
+	Flush(timeout time.Duration)
+}
+
+// reporter is the global reporter.
+var reporter Reporter = nopReporter{}
+
+// Init initializes the global reporter based on the error reporting configuration.
+func Init() error {
+	c := config.C.Load()
+	cfg := c.ErrorReporting
+
+	switch strings.ToLower(cfg.Reporter) {
+	case "", ReporterNone:
+		reporter = nopReporter{}
+	case ReporterLog:
+		reporter = NewLogReporter()
+	case ReporterSentry:
+		r, err := NewSentryReporter(cfg.DSN, c.General.Environment)
+		if err != nil {
+			return err
+		}
+
+		reporter = r
+	default:
+		return errors.Errorf("unknown error reporter %q", cfg.Reporter)
+	}
+
+	return nil
+}
+
+// Report reports the event using the global reporter.
+func Report(ctx context.Context, e Event) {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	reporter.Report(ctx, e)
+}
+
+// Flush flushes the global reporter.
+func Flush(timeout time.Duration) {
+	reporter.Flush(timeout)
+}
+
+// nopReporter drops all events.
+type nopReporter struct{}
+
+func (nopReporter) Report(context.Context, Event) {}
+
+func (nopReporter) Flush(time.Duration) {}
@@ -0,0 +1,222 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+)
+
+// sentryClientName is reported as the client of all events.
+const sentryClientName = "briefkasten-go/1.0"
+
+// sentryQueueSize is the number of events which can be queued, before events are dropped.
+const sentryQueueSize = 100
+
+// SentryReporter is a [Reporter] sending all events to a server implementing
+// the Sentry store protocol, i.e. Sentry or GlitchTip.
+type SentryReporter struct {
+	storeURL    string
+	authHeader  string
+	environment string
+	client      *http.Client
+
+	queue   chan sentryEvent
+	pending sync.WaitGroup
+}
+
+var _ Reporter = (*SentryReporter)(nil)
+
+// sentryEvent is the event payload of the Sentry store endpoint.
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger"`
+	Environment string            `json:"environment,omitempty"`
+	Message     string            `json:"message"`
+	Exception   sentryExceptions  `json:"exception"`
+	Tags        map[string]string `json:"tags"`
+	User        *sentryUser       `json:"user,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sentryUser struct {
+	ID string `json:"id"`
+}
+
+// NewSentryReporter returns a new [SentryReporter] for the given DSN,
+// i.e. "https://<key>@sentry.example.com/<project>".
+func NewSentryReporter(dsn, environment string) (*SentryReporter, error) {
+	storeURL, authHeader, err := parseSentryDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &SentryReporter{
+		storeURL:    storeURL,
+		authHeader:  authHeader,
+		environment: environment,
+		client:      &http.Client{Timeout: 5 * time.Second},
+		queue:       make(chan sentryEvent, sentryQueueSize),
+	}
+
+	go r.worker()
+
+	return r, nil
+}
+
+// parseSentryDSN returns the store URL and the auth header of the DSN.
+func parseSentryDSN(dsn string) (string, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", errors.Wrap(err, "unable to parse sentry DSN")
+	}
+
+	if u.User == nil || u.User.Username() == "" {
+		return "", "", errors.New("sentry DSN does not contain a public key")
+	}
+
+	dir, projectID := path.Split(strings.TrimSuffix(u.Path, "/"))
+	if projectID == "" {
+		return "", "", errors.New("sentry DSN does not contain a project ID")
+	}
+
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", sentryClientName, u.User.Username())
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+
+	store := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   path.Join(dir, "api", projectID, "store") + "/",
+	}
+
+	return store.String(), auth, nil
+}
+
+// Report implements [Reporter].
+// If the queue is full, the event is dropped.
+func (r *SentryReporter) Report(_ context.Context, e Event) {
+	ev := sentryEvent{
+		EventID:     newEventID(),
+		Timestamp:   e.Timestamp.UTC().Format(time.RFC3339),
+		Level:       "error",
+		Platform:    "go",
+		Logger:      "briefkasten",
+		Environment: r.environment,
+		Message:     e.Err.Error(),
+		Exception: sentryExceptions{Values: []sentryException{{
+			Type:  fmt.Sprintf("%T", errors.Cause(e.Err)),
+			Value: e.Err.Error(),
+		}}},
+		Tags: map[string]string{
+			"request_id": e.RequestID,
+			"method":     e.Method,
+			"route":      e.Route,
+		},
+	}
+
+	if e.UserID != "" {
+		ev.User = &sentryUser{ID: e.UserID}
+	}
+
+	if e.Stack != "" {
+		ev.Extra = map[string]string{"stack": e.Stack}
+	}
+
+	r.pending.Add(1)
+
+	select {
+	case r.queue <- ev:
+	default:
+		r.pending.Done()
+		log.Warn("Error reporting queue is full, dropping event", zap.String("event_id", ev.EventID))
+	}
+}
+
+// Flush implements [Reporter].
+func (r *SentryReporter) Flush(timeout time.Duration) {
+	done := make(chan struct{})
+
+	go func() {
+		r.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Warn("Timeout while flushing error reports")
+	}
+}
+
+// worker sends all queued events.
+func (r *SentryReporter) worker() {
+	for ev := range r.queue {
+		if err := r.send(ev); err != nil {
+			log.Warn("Unable to send error report", zap.Error(err), zap.String("event_id", ev.EventID))
+		}
+
+		r.pending.Done()
+	}
+}
+
+func (r *SentryReporter) send(ev sentryEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal event")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "unable to create request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", r.authHeader)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "unable to send event")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// newEventID returns a random, hex encoded 128 bit event ID.
+func newEventID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,57 @@
+package seed
+
+var firstNames = []string{
+	"Anna", "Ben", "Clara", "David", "Emma", "Felix", "Greta", "Hannah", "Jonas", "Julia",
+	"Karl", "Lea", "Lukas", "Marie", "Max", "Mia", "Noah", "Paul", "Sophie", "Tim",
+}
+
+var lastNames = []string{
+	"Becker", "Fischer", "Hoffmann", "Koch", "Meyer", "Müller", "Richter", "Schäfer",
+	"Schmidt", "Schneider", "Schulz", "Wagner", "Weber", "Wolf", "Zimmermann",
+}
+
+var categoryNames = []string{
+	"Reading List", "Work", "Recipes", "Travel", "Research", "Tutorials",
+	"Tools", "News", "Inspiration", "Shopping", "Music", "Videos",
+}
+
+var tagNames = []string{
+	"go", "rust", "python", "javascript", "kubernetes", "docker", "postgres", "linux",
+	"security", "design", "ux", "devops", "testing", "performance", "architecture",
+	"career", "productivity", "open-source", "machine-learning", "networking",
+}
+
+var tagEmojis = map[string]string{
+	"go":           "🐹",
+	"rust":         "🦀",
+	"python":       "🐍",
+	"docker":       "🐳",
+	"security":     "🔒",
+	"design":       "🎨",
+	"testing":      "🧪",
+	"performance":  "🚀",
+	"productivity": "⏱️",
+}
+
+var slugWords = []string{
+	"introduction", "guide", "tips", "patterns", "deep-dive", "best-practices",
+	"explained", "in-practice", "lessons-learned", "cheatsheet", "pitfalls", "basics",
+}
+
+// sites are the sites of the generated bookmarks.
+var sites = []struct {
+	name string
+	host string
+	path string
+}{
+	{"The Go Blog", "go.dev", "/blog/"},
+	{"GitHub", "github.com", "/topics/"},
+	{"Stack Overflow", "stackoverflow.com", "/questions/"},
+	{"Hacker News", "news.ycombinator.com", "/item/"},
+	{"Wikipedia", "en.wikipedia.org", "/wiki/"},
+	{"MDN", "developer.mozilla.org", "/en-US/docs/"},
+	{"dev.to", "dev.to", "/t/"},
+	{"Medium", "medium.com", "/tag/"},
+	{"YouTube", "www.youtube.com", "/results?search_query="},
+	{"Smashing Magazine", "www.smashingmagazine.com", "/category/"},
+}
@@ -0,0 +1,236 @@
+// Package seed populates a development database with generated data.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// Options configures the amount of generated data.
+// All numbers must not be negative.
+type Options struct {
+	// Users is the number of users.
+	Users int
+	// Categories is the number of categories per user.
+	Categories int
+	// Tags is the number of tags per user.
+	Tags int
+	// Bookmarks is the number of bookmarks per user.
+	Bookmarks int
+	// MaxTagsPerBookmark is the maximum number of tags of a bookmark.
+	MaxTagsPerBookmark int
+	// Seed is the seed of the random generator,
+	// the same seed generates the same data.
+	Seed int64
+	// Now is the time of the newest generated record,
+	// the creation times are spread over the year before.
+	Now time.Time
+}
+
+// Result holds the number of created records.
+type Result struct {
+	Users      int
+	Categories int
+	Tags       int
+	Bookmarks  int
+}
+
+// Run generates the data and inserts it in a single transaction.
+//
+// The generated users have email addresses of the "example.com" domain,
+// seeding a database twice therefore fails with [store.ErrConflict].
+func Run(ctx context.Context, s store.Store, opts Options) (Result, error) {
+	g := generator{
+		rng:  rand.New(rand.NewSource(opts.Seed)), //nolint:gosec // not used for security
+		opts: opts,
+	}
+
+	var res Result
+
+	err := s.RunInTx(ctx, func(ctx context.Context) error {
+		res = Result{}
+
+		for i := 0; i < opts.Users; i++ {
+			if err := g.user(ctx, s, i, &res); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if errors.Is(err, store.ErrConflict) {
+		return Result{}, errors.Wrap(err, "database is already seeded, reset it first")
+	}
+
+	return res, err
+}
+
+// generator generates random records.
+type generator struct {
+	rng  *rand.Rand
+	opts Options
+}
+
+// user creates the i-th user including all of its data.
+func (g *generator) user(ctx context.Context, s store.Store, i int, res *Result) error {
+	first, last := g.pick(firstNames), g.pick(lastNames)
+
+	u := models.UserAccount{
+		ID:            g.uuid(),
+		CreatedAt:     g.createdAt(),
+		Name:          first + " " + last,
+		Email:         fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), i+1),
+		EmailVerified: g.rng.Intn(10) > 0,
+	}
+
+	if err := s.Users.Create(ctx, &u); err != nil {
+		return err
+	}
+	res.Users++
+
+	categories := make([]uuid.UUID, 0, g.opts.Categories)
+	for _, name := range g.distinct(categoryNames, g.opts.Categories) {
+		c := models.Category{
+			ID:        g.uuid(),
+			CreatedAt: g.createdAt(),
+			UserID:    u.ID,
+			Name:      name,
+		}
+
+		if g.rng.Intn(2) == 0 {
+			c.Description = null.StringFrom("Everything about " + strings.ToLower(name))
+		}
+
+		if err := s.Categories.Create(ctx, &c); err != nil {
+			return err
+		}
+
+		categories = append(categories, c.ID)
+		res.Categories++
+	}
+
+	tags := make([]uuid.UUID, 0, g.opts.Tags)
+	for _, name := range g.distinct(tagNames, g.opts.Tags) {
+		t := models.Tag{
+			ID:        g.uuid(),
+			CreatedAt: g.createdAt(),
+			UserID:    u.ID,
+			Name:      name,
+		}
+
+		if emoji := tagEmojis[name]; emoji != "" {
+			t.Emoji = null.StringFrom(emoji)
+		}
+
+		if err := s.Tags.Create(ctx, &t); err != nil {
+			return err
+		}
+
+		tags = append(tags, t.ID)
+		res.Tags++
+	}
+
+	// bookmarks require a category
+	if len(categories) == 0 {
+		return nil
+	}
+
+	for j := 0; j < g.opts.Bookmarks; j++ {
+		site := sites[g.rng.Intn(len(sites))]
+		slug := g.pick(tagNames) + "-" + g.pick(slugWords) + "-" + g.pick(slugWords)
+
+		b := models.Bookmark{
+			ID:          g.uuid(),
+			CreatedAt:   g.createdAt(),
+			UserID:      u.ID,
+			CategoryID:  categories[g.rng.Intn(len(categories))],
+			URL:         "https://" + site.host + site.path + slug,
+			Description: null.StringFrom(site.name + ": " + strings.ReplaceAll(slug, "-", " ")),
+		}
+
+		if g.rng.Intn(3) == 0 {
+			b.Image = null.StringFrom("https://" + site.host + "/images/" + slug + ".png")
+		}
+
+		if err := s.Bookmarks.Create(ctx, &b); err != nil {
+			return err
+		}
+		res.Bookmarks++
+
+		if len(tags) == 0 || g.opts.MaxTagsPerBookmark <= 0 {
+			continue
+		}
+
+		n := g.rng.Intn(g.opts.MaxTagsPerBookmark + 1)
+		if n > len(tags) {
+			n = len(tags)
+		}
+
+		ids := make([]uuid.UUID, n)
+		for k, idx := range g.rng.Perm(len(tags))[:n] {
+			ids[k] = tags[idx]
+		}
+
+		if err := s.Bookmarks.SetTags(ctx, b.ID, ids); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// uuid returns a random UUID of the generator.
+func (g *generator) uuid() uuid.UUID {
+	id, err := uuid.NewRandomFromReader(g.rng)
+	if err != nil {
+		// the reader never fails
+		panic(err)
+	}
+
+	return id
+}
+
+// createdAt returns a random time of the year before Now.
+func (g *generator) createdAt() time.Time {
+	const year = 365 * 24 * time.Hour
+
+	return g.opts.Now.Add(-time.Duration(g.rng.Int63n(int64(year)))).UTC().Truncate(time.Microsecond)
+}
+
+// pick returns a random element.
+func (g *generator) pick(list []string) string {
+	return list[g.rng.Intn(len(list))]
+}
+
+// distinct returns n distinct random elements. If n exceeds the length of
+// the list, a number is appended to the repeated elements.
+func (g *generator) distinct(list []string, n int) []string {
+	res := make([]string, 0, n)
+
+	for i := 0; len(res) < n; i++ {
+		for _, idx := range g.rng.Perm(len(list)) {
+			if len(res) == n {
+				break
+			}
+
+			name := list[idx]
+			if i > 0 {
+				name = fmt.Sprintf("%s %d", name, i+1)
+			}
+
+			res = append(res, name)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,118 @@
+package seed_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/seed"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store/memstore"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+func TestRunDeterministic(t *testing.T) {
+	opts := seed.Options{
+		Users:              3,
+		Categories:         2,
+		Tags:               4,
+		Bookmarks:          5,
+		MaxTagsPerBookmark: 3,
+		Seed:               42,
+		Now:                time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	first := generate(t, opts)
+	second := generate(t, opts)
+
+	if !reflect.DeepEqual(first, second) {
+		t.Error("expected the same data for the same seed")
+	}
+
+	if len(first.users) != 3 || len(first.bookmarks) != 15 {
+		t.Fatalf("expected 3 users and 15 bookmarks, got %d and %d", len(first.users), len(first.bookmarks))
+	}
+
+	opts.Seed = 43
+	if other := generate(t, opts); reflect.DeepEqual(first, other) {
+		t.Error("expected different data for another seed")
+	}
+}
+
+func TestRunTwice(t *testing.T) {
+	s := memstore.New()
+	opts := seed.Options{Users: 1, Seed: 1, Now: time.Now()}
+
+	if _, err := seed.Run(context.Background(), s, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := seed.Run(context.Background(), s, opts); err == nil {
+		t.Error("expected an error seeding the database twice")
+	}
+}
+
+// data holds all generated records.
+type data struct {
+	users      []models.UserAccount
+	categories []models.Category
+	tags       []models.Tag
+	bookmarks  []models.Bookmark
+	// bookmarkTags maps the bookmark IDs to their tag IDs.
+	bookmarkTags map[uuid.UUID][]uuid.UUID
+}
+
+// generate seeds an empty store and returns all generated records.
+func generate(t *testing.T, opts seed.Options) data {
+	t.Helper()
+
+	ctx := context.Background()
+	s := memstore.New()
+
+	res, err := seed.Run(ctx, s, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := data{bookmarkTags: make(map[uuid.UUID][]uuid.UUID)}
+
+	if d.users, err = s.Users.List(ctx, "", store.Page{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, u := range d.users {
+		categories, err := s.Categories.List(ctx, u.ID, store.Page{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tags, err := s.Tags.List(ctx, u.ID, store.Page{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bookmarks, err := s.Bookmarks.List(ctx, u.ID, store.Page{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, b := range bookmarks {
+			if d.bookmarkTags[b.ID], err = s.Bookmarks.TagIDs(ctx, b.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		d.categories = append(d.categories, categories...)
+		d.tags = append(d.tags, tags...)
+		d.bookmarks = append(d.bookmarks, bookmarks...)
+	}
+
+	if res.Users != len(d.users) || res.Categories != len(d.categories) || res.Tags != len(d.tags) || res.Bookmarks != len(d.bookmarks) {
+		t.Fatalf("result %+v does not match the stored records", res)
+	}
+
+	return d
+}
@@ -0,0 +1,173 @@
+package bunstore
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+type bookmarkStore struct {
+	conn
+}
+
+var _ store.BookmarkStore = (*bookmarkStore)(nil)
+
+// List implements [store.BookmarkStore].
+func (s *bookmarkStore) List(ctx context.Context, userID uuid.UUID, page store.Page) ([]models.Bookmark, error) {
+	ret := make([]models.Bookmark, 0, page.Size())
+
+	q := s.idb(ctx).NewSelect().
+		Model(&ret).
+		Where("user_id = ?", userID)
+
+	err := applyPage(q, page).Scan(ctx)
+
+	return ret, wrapErr(err, "unable to retrieve bookmarks")
+}
+
+// Get implements [store.BookmarkStore].
+func (s *bookmarkStore) Get(ctx context.Context, userID, id uuid.UUID) (models.Bookmark, error) {
+	var b models.Bookmark
+
+	err := s.idb(ctx).NewSelect().
+		Model(&b).
+		Where("user_id = ?", userID).
+		Where("id = ?", id).
+		Scan(ctx)
+
+	return b, wrapErr(err, "unable to retrieve bookmark")
+}
+
+// Create implements [store.BookmarkStore].
+func (s *bookmarkStore) Create(ctx context.Context, b *models.Bookmark) error {
+	_, err := s.idb(ctx).NewInsert().
+		Model(b).
+		Returning("*").
+		Exec(ctx)
+
+	return wrapErr(err, "unable to insert bookmark")
+}
+
+// Update implements [store.BookmarkStore].
+func (s *bookmarkStore) Update(ctx context.Context, b *models.Bookmark) error {
+	// NOTE: Some fields are immutable.
+	var cols []string
+
+	if b.CategoryID != uuid.Nil {
+		cols = append(cols, "category_id")
+	}
+	if b.URL != "" {
+		cols = append(cols, "url")
+	}
+	if !b.Image.IsZero() {
+		cols = append(cols, "image")
+	}
+	if !b.Description.IsZero() {
+		cols = append(cols, "description")
+	}
+
+	if len(cols) == 0 {
+		cur, err := s.Get(ctx, b.UserID, b.ID)
+		*b = cur
+
+		return err
+	}
+
+	res, err := s.idb(ctx).NewUpdate().
+		Model(b).
+		Column(cols...).
+		Where("user_id = ?", b.UserID).
+		Where("id = ?", b.ID).
+		Returning("*").
+		Exec(ctx)
+
+	return checkAffected(res, err, "unable to update bookmark")
+}
+
+// Delete implements [store.BookmarkStore].
+func (s *bookmarkStore) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	res, err := s.idb(ctx).NewDelete().
+		Model((*models.Bookmark)(nil)).
+		Where("user_id = ?", userID).
+		Where("id = ?", id).
+		Exec(ctx)
+
+	return checkAffected(res, err, "unable to delete bookmark")
+}
+
+// SetTags implements [store.BookmarkStore].
+func (s *bookmarkStore) SetTags(ctx context.Context, bookmarkID uuid.UUID, tagIDs []uuid.UUID) error {
+	// the links must be replaced at once
+	return s.RunInTx(ctx, func(ctx context.Context) error {
+		_, err := s.idb(ctx).NewDelete().
+			Model((*models.TagOnBookmark)(nil)).
+			Where("bookmark_id = ?", bookmarkID).
+			Exec(ctx)
+		if err != nil {
+			return wrapErr(err, "unable to delete bookmark tags")
+		}
+
+		if len(tagIDs) == 0 {
+			return nil
+		}
+
+		links := make([]models.TagOnBookmark, len(tagIDs))
+		for i, id := range tagIDs {
+			links[i] = models.TagOnBookmark{BookmarkID: bookmarkID, TagID: id}
+		}
+
+		_, err = s.idb(ctx).NewInsert().
+			Model(&links).
+			Exec(ctx)
+
+		return wrapErr(err, "unable to insert bookmark tags")
+	})
+}
+
+// TagIDs implements [store.BookmarkStore].
+func (s *bookmarkStore) TagIDs(ctx context.Context, bookmarkID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+
+	err := s.idb(ctx).NewSelect().
+		Model((*models.TagOnBookmark)(nil)).
+		Column("tag_id").
+		Where("bookmark_id = ?", bookmarkID).
+		Scan(ctx, &ids)
+
+	return ids, wrapErr(err, "unable to retrieve bookmark tags")
+}
+
+// ListRemoteImages implements [store.BookmarkStore].
+func (s *bookmarkStore) ListRemoteImages(ctx context.Context, page store.Page) ([]models.Bookmark, error) {
+	ret := make([]models.Bookmark, 0, page.Size())
+
+	q := s.idb(ctx).NewSelect().
+		Model(&ret).
+		Where("image IS NOT NULL").
+		Where("image NOT LIKE ?", blob.RefPrefix+"%")
+
+	err := applyPage(q, page).Scan(ctx)
+
+	return ret, wrapErr(err, "unable to retrieve bookmarks with remote images")
+}
+
+// ReplaceImage implements [store.BookmarkStore].
+func (s *bookmarkStore) ReplaceImage(ctx context.Context, id uuid.UUID, oldImage, newImage string) error {
+	res, err := s.idb(ctx).NewUpdate().
+		Model((*models.Bookmark)(nil)).
+		Set("image = ?", newImage).
+		Where("id = ?", id).
+		Where("image = ?", oldImage).
+		Exec(ctx)
+
+	return checkAffected(res, err, "unable to replace bookmark image")
+}
+
+// BlobRefs implements [store.BookmarkStore].
+func (s *bookmarkStore) BlobRefs(ctx context.Context) ([]string, error) {
+	return s.blobRefs(ctx, (*models.Bookmark)(nil))
+}
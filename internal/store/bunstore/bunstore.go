@@ -0,0 +1,107 @@
+// Package bunstore implements the stores using bun.
+package bunstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// conn is the base of all stores, it resolves the database
+// connection or the transaction of the context.
+type conn struct {
+	db *bun.DB
+}
+
+// New returns the stores using the given database.
+func New(db *bun.DB) store.Store {
+	c := conn{db: db}
+
+	return store.Store{
+		Users:      &userStore{c},
+		Categories: &categoryStore{c},
+		Bookmarks:  &bookmarkStore{c},
+		Tags:       &tagStore{c},
+		UnitOfWork: c,
+	}
+}
+
+// RunInTx implements [store.UnitOfWork].
+// The transaction is carried by the context, see [models.ContextWithTx].
+func (c conn) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return models.RunInTx(ctx, c.db, fn)
+}
+
+// idb returns the transaction of the context, or the database if there is none.
+func (c conn) idb(ctx context.Context) bun.IDB {
+	if tx, ok := models.TxFromContext(ctx); ok {
+		return tx
+	}
+
+	return c.db
+}
+
+// wrapErr wraps the error and maps the database errors to
+// [store.ErrNotFound] and [store.ErrConflict].
+func wrapErr(err error, msg string) error {
+	switch {
+	case err == nil:
+		return nil
+	case models.IsNoRows(err):
+		return errors.Wrap(store.ErrNotFound, msg)
+	case models.IsUniqueViolationErr(err):
+		return errors.Wrapf(store.ErrConflict, "%s: %v", msg, err)
+	default:
+		return errors.Wrap(err, msg)
+	}
+}
+
+// checkAffected returns [store.ErrNotFound] if no row has been affected.
+func checkAffected(res sql.Result, err error, msg string) error {
+	if err != nil {
+		return wrapErr(err, msg)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, msg)
+	}
+
+	if n == 0 {
+		return errors.Wrap(store.ErrNotFound, msg)
+	}
+
+	return nil
+}
+
+// applyPage applies the pagination to the query of the given table alias.
+func applyPage(q *bun.SelectQuery, page store.Page) *bun.SelectQuery {
+	q = q.OrderExpr("created_at DESC, id DESC").
+		Limit(page.Size())
+
+	if !page.IsFirst() {
+		q = q.Where("(created_at, id) < (?, ?)", page.AfterTime, page.AfterID)
+	}
+
+	return q
+}
+
+// blobRefs returns the distinct images of the model's table, which reference a blob.
+func (c conn) blobRefs(ctx context.Context, model any) ([]string, error) {
+	var refs []string
+
+	err := c.idb(ctx).NewSelect().
+		Model(model).
+		Distinct().
+		Column("image").
+		Where("image LIKE ?", blob.RefPrefix+"%").
+		Scan(ctx, &refs)
+
+	return refs, wrapErr(err, "unable to retrieve blob references")
+}
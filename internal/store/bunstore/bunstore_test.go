@@ -0,0 +1,16 @@
+package bunstore_test
+
+import (
+	"testing"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/apitest"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store/storetest"
+)
+
+// TestStore uses the database of the API tests, see [apitest.DBURIEnv].
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return apitest.New(t, nil).Store
+	})
+}
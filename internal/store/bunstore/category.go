@@ -0,0 +1,93 @@
+package bunstore
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+type categoryStore struct {
+	conn
+}
+
+var _ store.CategoryStore = (*categoryStore)(nil)
+
+// List implements [store.CategoryStore].
+func (s *categoryStore) List(ctx context.Context, userID uuid.UUID, page store.Page) ([]models.Category, error) {
+	ret := make([]models.Category, 0, page.Size())
+
+	q := s.idb(ctx).NewSelect().
+		Model(&ret).
+		Where("user_id = ?", userID)
+
+	err := applyPage(q, page).Scan(ctx)
+
+	return ret, wrapErr(err, "unable to retrieve categories")
+}
+
+// Get implements [store.CategoryStore].
+func (s *categoryStore) Get(ctx context.Context, userID, id uuid.UUID) (models.Category, error) {
+	var c models.Category
+
+	err := s.idb(ctx).NewSelect().
+		Model(&c).
+		Where("user_id = ?", userID).
+		Where("id = ?", id).
+		Scan(ctx)
+
+	return c, wrapErr(err, "unable to retrieve category")
+}
+
+// Create implements [store.CategoryStore].
+func (s *categoryStore) Create(ctx context.Context, c *models.Category) error {
+	_, err := s.idb(ctx).NewInsert().
+		Model(c).
+		Returning("*").
+		Exec(ctx)
+
+	return wrapErr(err, "unable to insert category")
+}
+
+// Update implements [store.CategoryStore].
+func (s *categoryStore) Update(ctx context.Context, c *models.Category) error {
+	// NOTE: Some fields are immutable.
+	var cols []string
+
+	if c.Name != "" {
+		cols = append(cols, "name")
+	}
+	if !c.Description.IsZero() {
+		cols = append(cols, "description")
+	}
+
+	if len(cols) == 0 {
+		cur, err := s.Get(ctx, c.UserID, c.ID)
+		*c = cur
+
+		return err
+	}
+
+	res, err := s.idb(ctx).NewUpdate().
+		Model(c).
+		Column(cols...).
+		Where("user_id = ?", c.UserID).
+		Where("id = ?", c.ID).
+		Returning("*").
+		Exec(ctx)
+
+	return checkAffected(res, err, "unable to update category")
+}
+
+// Delete implements [store.CategoryStore].
+func (s *categoryStore) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	res, err := s.idb(ctx).NewDelete().
+		Model((*models.Category)(nil)).
+		Where("user_id = ?", userID).
+		Where("id = ?", id).
+		Exec(ctx)
+
+	return checkAffected(res, err, "unable to delete category")
+}
@@ -0,0 +1,109 @@
+package bunstore
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+type tagStore struct {
+	conn
+}
+
+var _ store.TagStore = (*tagStore)(nil)
+
+// List implements [store.TagStore].
+func (s *tagStore) List(ctx context.Context, userID uuid.UUID, page store.Page) ([]models.Tag, error) {
+	ret := make([]models.Tag, 0, page.Size())
+
+	q := s.idb(ctx).NewSelect().
+		Model(&ret).
+		Where("user_id = ?", userID)
+
+	err := applyPage(q, page).Scan(ctx)
+
+	return ret, wrapErr(err, "unable to retrieve tags")
+}
+
+// Get implements [store.TagStore].
+func (s *tagStore) Get(ctx context.Context, userID, id uuid.UUID) (models.Tag, error) {
+	var t models.Tag
+
+	err := s.idb(ctx).NewSelect().
+		Model(&t).
+		Where("user_id = ?", userID).
+		Where("id = ?", id).
+		Scan(ctx)
+
+	return t, wrapErr(err, "unable to retrieve tag")
+}
+
+// GetByName implements [store.TagStore].
+func (s *tagStore) GetByName(ctx context.Context, userID uuid.UUID, name string) (models.Tag, error) {
+	var t models.Tag
+
+	err := s.idb(ctx).NewSelect().
+		Model(&t).
+		Where("user_id = ?", userID).
+		Where("name = ?", name).
+		Scan(ctx)
+
+	return t, wrapErr(err, "unable to retrieve tag")
+}
+
+// Create implements [store.TagStore].
+func (s *tagStore) Create(ctx context.Context, t *models.Tag) error {
+	_, err := s.idb(ctx).NewInsert().
+		Model(t).
+		Returning("*").
+		Exec(ctx)
+
+	return wrapErr(err, "unable to insert tag")
+}
+
+// Update implements [store.TagStore].
+func (s *tagStore) Update(ctx context.Context, t *models.Tag) error {
+	// NOTE: Some fields are immutable.
+	var cols []string
+
+	if t.Name != "" {
+		cols = append(cols, "name")
+	}
+	if !t.Description.IsZero() {
+		cols = append(cols, "description")
+	}
+	if !t.Emoji.IsZero() {
+		cols = append(cols, "emoji")
+	}
+
+	if len(cols) == 0 {
+		cur, err := s.Get(ctx, t.UserID, t.ID)
+		*t = cur
+
+		return err
+	}
+
+	res, err := s.idb(ctx).NewUpdate().
+		Model(t).
+		Column(cols...).
+		Where("user_id = ?", t.UserID).
+		Where("id = ?", t.ID).
+		Returning("*").
+		Exec(ctx)
+
+	return checkAffected(res, err, "unable to update tag")
+}
+
+// Delete implements [store.TagStore].
+func (s *tagStore) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	res, err := s.idb(ctx).NewDelete().
+		Model((*models.Tag)(nil)).
+		Where("user_id = ?", userID).
+		Where("id = ?", id).
+		Exec(ctx)
+
+	return checkAffected(res, err, "unable to delete tag")
+}
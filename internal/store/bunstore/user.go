@@ -0,0 +1,225 @@
+package bunstore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+type userStore struct {
+	conn
+}
+
+var _ store.UserStore = (*userStore)(nil)
+
+// Get implements [store.UserStore].
+func (s *userStore) Get(ctx context.Context, id uuid.UUID) (models.UserAccount, error) {
+	var u models.UserAccount
+
+	err := s.idb(ctx).NewSelect().
+		Model(&u).
+		Where("id = ?", id).
+		Scan(ctx)
+
+	return u, wrapErr(err, "unable to find user in DB")
+}
+
+// GetByEmail implements [store.UserStore].
+func (s *userStore) GetByEmail(ctx context.Context, email string) (models.UserAccount, error) {
+	var u models.UserAccount
+
+	err := s.idb(ctx).NewSelect().
+		Model(&u).
+		Where("email = ?", email).
+		Limit(1).
+		Scan(ctx)
+
+	return u, wrapErr(err, "unable to find user in DB")
+}
+
+// Create implements [store.UserStore].
+func (s *userStore) Create(ctx context.Context, u *models.UserAccount) error {
+	_, err := s.idb(ctx).NewInsert().
+		Model(u).
+		Returning("*").
+		Exec(ctx)
+
+	return wrapErr(err, "unable to insert user into DB")
+}
+
+// GetOrCreate implements [store.UserStore].
+func (s *userStore) GetOrCreate(ctx context.Context, u models.UserAccount) (models.UserAccount, error) {
+	ret, err := s.GetByEmail(ctx, u.Email)
+	if errors.Is(err, store.ErrNotFound) {
+		return u, s.Create(ctx, &u)
+	}
+
+	return ret, err
+}
+
+// List implements [store.UserStore].
+func (s *userStore) List(ctx context.Context, query string, page store.Page) ([]models.UserAccount, error) {
+	users := make([]models.UserAccount, 0)
+
+	q := s.idb(ctx).NewSelect().
+		Model(&users)
+
+	if query != "" {
+		pattern := "%" + escapeLike(strings.ToLower(query)) + "%"
+
+		q = q.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where(`LOWER(name) LIKE ? ESCAPE '\'`, pattern).
+				WhereOr(`LOWER(email) LIKE ? ESCAPE '\'`, pattern)
+		})
+	}
+
+	err := applyPage(q, page).Scan(ctx)
+
+	return users, wrapErr(err, "unable to list users")
+}
+
+// SetRole implements [store.UserStore].
+func (s *userStore) SetRole(ctx context.Context, id uuid.UUID, role string) error {
+	res, err := s.idb(ctx).NewUpdate().
+		Model((*models.UserAccount)(nil)).
+		Set("role = ?", role).
+		Set("token_generation = token_generation + 1").
+		Where("id = ?", id).
+		Exec(ctx)
+
+	return checkAffected(res, err, "unable to set user role")
+}
+
+// RevokeTokens implements [store.UserStore].
+func (s *userStore) RevokeTokens(ctx context.Context, id uuid.UUID) error {
+	res, err := s.idb(ctx).NewUpdate().
+		Model((*models.UserAccount)(nil)).
+		Set("token_generation = token_generation + 1").
+		Where("id = ?", id).
+		Exec(ctx)
+
+	return checkAffected(res, err, "unable to revoke user tokens")
+}
+
+// Counts implements [store.UserStore].
+func (s *userStore) Counts(ctx context.Context, id uuid.UUID) (store.UserCounts, error) {
+	var c store.UserCounts
+
+	if _, err := s.Get(ctx, id); err != nil {
+		return c, err
+	}
+
+	count := func(model any) (int, error) {
+		n, err := s.idb(ctx).NewSelect().
+			Model(model).
+			Where("user_id = ?", id).
+			Count(ctx)
+
+		return n, wrapErr(err, "unable to count user records")
+	}
+
+	var err error
+
+	if c.Categories, err = count((*models.Category)(nil)); err != nil {
+		return c, err
+	}
+	if c.Tags, err = count((*models.Tag)(nil)); err != nil {
+		return c, err
+	}
+	c.Bookmarks, err = count((*models.Bookmark)(nil))
+
+	return c, err
+}
+
+// SetDisabled implements [store.UserStore].
+func (s *userStore) SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) error {
+	q := s.idb(ctx).NewUpdate().
+		Model((*models.UserAccount)(nil)).
+		Where("id = ?", id)
+
+	if disabled {
+		// keep the time of the first deactivation
+		q = q.Set("disabled_at = COALESCE(disabled_at, ?)", time.Now().UTC())
+	} else {
+		q = q.Set("disabled_at = NULL")
+	}
+
+	res, err := q.Exec(ctx)
+
+	return checkAffected(res, err, "unable to disable user")
+}
+
+// UpdateProfile implements [store.UserStore].
+func (s *userStore) UpdateProfile(ctx context.Context, id uuid.UUID, p store.ProfileUpdate) (models.UserAccount, error) {
+	if p == (store.ProfileUpdate{}) {
+		return s.Get(ctx, id)
+	}
+
+	var u models.UserAccount
+
+	q := s.idb(ctx).NewUpdate().
+		Model(&u).
+		Where("id = ?", id).
+		Returning("*")
+
+	if p.Name != nil {
+		q = q.Set("name = ?", *p.Name)
+	}
+	if p.Image != nil {
+		q = q.Set("image = ?", *p.Image)
+	}
+	if p.PreferredLanguage != nil {
+		q = q.Set("preferred_language = ?", *p.PreferredLanguage)
+	}
+	if p.Timezone != nil {
+		q = q.Set("timezone = ?", *p.Timezone)
+	}
+	if p.DefaultCategoryID != nil {
+		q = q.Set("default_category_id = ?", *p.DefaultCategoryID)
+	}
+
+	res, err := q.Exec(ctx)
+
+	return u, checkAffected(res, err, "unable to update user profile")
+}
+
+// BlobRefs implements [store.UserStore].
+func (s *userStore) BlobRefs(ctx context.Context) ([]string, error) {
+	return s.blobRefs(ctx, (*models.UserAccount)(nil))
+}
+
+// Sessions implements [store.UserStore].
+func (s *userStore) Sessions(ctx context.Context, id uuid.UUID) ([]models.Session, error) {
+	var ret []models.Session
+
+	err := s.idb(ctx).NewSelect().
+		Model(&ret).
+		Where("user_id = ?", id).
+		Order("expires DESC").
+		Scan(ctx)
+
+	return ret, wrapErr(err, "unable to retrieve user sessions")
+}
+
+// Delete implements [store.UserStore].
+// The data of the user is deleted by the ON DELETE CASCADE foreign keys.
+func (s *userStore) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := s.idb(ctx).NewDelete().
+		Model((*models.UserAccount)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+
+	return checkAffected(res, err, "unable to delete user")
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
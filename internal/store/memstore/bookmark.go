@@ -0,0 +1,216 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+type bookmarkStore struct {
+	*db
+}
+
+var _ store.BookmarkStore = (*bookmarkStore)(nil)
+
+func bookmarkKey(b models.Bookmark) (uuid.UUID, uuid.UUID, time.Time) {
+	return b.UserID, b.ID, b.CreatedAt
+}
+
+// List implements [store.BookmarkStore].
+func (s *bookmarkStore) List(ctx context.Context, userID uuid.UUID, page store.Page) (ret []models.Bookmark, err error) {
+	s.read(ctx, func(st *state) {
+		ret = paginate(st.bookmarks, page, bookmarkKey, userID)
+	})
+
+	return ret, nil
+}
+
+// Get implements [store.BookmarkStore].
+func (s *bookmarkStore) Get(ctx context.Context, userID, id uuid.UUID) (b models.Bookmark, err error) {
+	s.read(ctx, func(st *state) {
+		var ok bool
+		if b, ok = st.bookmarks[id]; !ok || b.UserID != userID {
+			b, err = models.Bookmark{}, notFound("unable to retrieve bookmark")
+		}
+	})
+
+	return b, err
+}
+
+// Create implements [store.BookmarkStore].
+func (s *bookmarkStore) Create(ctx context.Context, b *models.Bookmark) error {
+	return s.write(ctx, func(st *state) error {
+		initRecord(&b.ID, &b.CreatedAt)
+
+		if err := checkBookmarkRefs(st, *b); err != nil {
+			return err
+		}
+
+		if _, ok := st.bookmarks[b.ID]; ok || bookmarkURLExists(st, *b) {
+			return conflict("unable to insert bookmark")
+		}
+
+		st.bookmarks[b.ID] = *b
+
+		return nil
+	})
+}
+
+// Update implements [store.BookmarkStore].
+func (s *bookmarkStore) Update(ctx context.Context, b *models.Bookmark) error {
+	return s.write(ctx, func(st *state) error {
+		cur, ok := st.bookmarks[b.ID]
+		if !ok || cur.UserID != b.UserID {
+			return notFound("unable to update bookmark")
+		}
+
+		// NOTE: Some fields are immutable.
+		if b.CategoryID != uuid.Nil {
+			cur.CategoryID = b.CategoryID
+		}
+		if b.URL != "" {
+			cur.URL = b.URL
+		}
+		if !b.Image.IsZero() {
+			cur.Image = b.Image
+		}
+		if !b.Description.IsZero() {
+			cur.Description = b.Description
+		}
+
+		if err := checkBookmarkRefs(st, cur); err != nil {
+			return err
+		}
+
+		if bookmarkURLExists(st, cur) {
+			return conflict("unable to update bookmark")
+		}
+
+		st.bookmarks[cur.ID] = cur
+		*b = cur
+
+		return nil
+	})
+}
+
+// Delete implements [store.BookmarkStore].
+func (s *bookmarkStore) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return s.write(ctx, func(st *state) error {
+		if b, ok := st.bookmarks[id]; !ok || b.UserID != userID {
+			return notFound("unable to delete bookmark")
+		}
+
+		delete(st.bookmarks, id)
+		// ON DELETE CASCADE
+		delete(st.bookmarkTags, id)
+
+		return nil
+	})
+}
+
+// SetTags implements [store.BookmarkStore].
+func (s *bookmarkStore) SetTags(ctx context.Context, bookmarkID uuid.UUID, tagIDs []uuid.UUID) error {
+	return s.write(ctx, func(st *state) error {
+		if _, ok := st.bookmarks[bookmarkID]; !ok {
+			return notFound("bookmark of tags does not exist")
+		}
+
+		for _, id := range tagIDs {
+			if _, ok := st.tags[id]; !ok {
+				return notFound("tag of bookmark does not exist")
+			}
+		}
+
+		st.bookmarkTags[bookmarkID] = append([]uuid.UUID(nil), tagIDs...)
+
+		return nil
+	})
+}
+
+// TagIDs implements [store.BookmarkStore].
+func (s *bookmarkStore) TagIDs(ctx context.Context, bookmarkID uuid.UUID) (ids []uuid.UUID, err error) {
+	s.read(ctx, func(st *state) {
+		ids = append(ids, st.bookmarkTags[bookmarkID]...)
+	})
+
+	return ids, nil
+}
+
+// checkBookmarkRefs checks the foreign keys of the bookmark.
+func checkBookmarkRefs(st *state, b models.Bookmark) error {
+	if _, ok := st.users[b.UserID]; !ok {
+		return notFound("user of bookmark does not exist")
+	}
+
+	if _, ok := st.categories[b.CategoryID]; !ok {
+		return notFound("category of bookmark does not exist")
+	}
+
+	return nil
+}
+
+// bookmarkURLExists returns true if another bookmark of the user has the same URL.
+func bookmarkURLExists(st *state, b models.Bookmark) bool {
+	for _, v := range st.bookmarks {
+		if v.ID != b.ID && v.UserID == b.UserID && v.URL == b.URL {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListRemoteImages implements [store.BookmarkStore].
+func (s *bookmarkStore) ListRemoteImages(ctx context.Context, page store.Page) (ret []models.Bookmark, err error) {
+	s.read(ctx, func(st *state) {
+		remote := make(map[uuid.UUID]models.Bookmark)
+
+		for id, b := range st.bookmarks {
+			if b.Image.Valid && !blob.IsRef(b.Image.String) {
+				remote[id] = b
+			}
+		}
+
+		// the bookmarks of all users are listed
+		ret = paginate(remote, page, func(b models.Bookmark) (uuid.UUID, uuid.UUID, time.Time) {
+			return uuid.Nil, b.ID, b.CreatedAt
+		}, uuid.Nil)
+	})
+
+	return ret, nil
+}
+
+// ReplaceImage implements [store.BookmarkStore].
+func (s *bookmarkStore) ReplaceImage(ctx context.Context, id uuid.UUID, oldImage, newImage string) error {
+	return s.write(ctx, func(st *state) error {
+		b, ok := st.bookmarks[id]
+		if !ok || !b.Image.Valid || b.Image.String != oldImage {
+			return notFound("unable to replace bookmark image")
+		}
+
+		b.Image = null.StringFrom(newImage)
+		st.bookmarks[id] = b
+
+		return nil
+	})
+}
+
+// BlobRefs implements [store.BookmarkStore].
+func (s *bookmarkStore) BlobRefs(ctx context.Context) (refs []string, err error) {
+	s.read(ctx, func(st *state) {
+		images := make([]null.String, 0, len(st.bookmarks))
+		for _, b := range st.bookmarks {
+			images = append(images, b.Image)
+		}
+
+		refs = blobRefs(images)
+	})
+
+	return refs, nil
+}
@@ -0,0 +1,128 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+type categoryStore struct {
+	*db
+}
+
+var _ store.CategoryStore = (*categoryStore)(nil)
+
+func categoryKey(c models.Category) (uuid.UUID, uuid.UUID, time.Time) {
+	return c.UserID, c.ID, c.CreatedAt
+}
+
+// List implements [store.CategoryStore].
+func (s *categoryStore) List(ctx context.Context, userID uuid.UUID, page store.Page) (ret []models.Category, err error) {
+	s.read(ctx, func(st *state) {
+		ret = paginate(st.categories, page, categoryKey, userID)
+	})
+
+	return ret, nil
+}
+
+// Get implements [store.CategoryStore].
+func (s *categoryStore) Get(ctx context.Context, userID, id uuid.UUID) (c models.Category, err error) {
+	s.read(ctx, func(st *state) {
+		var ok bool
+		if c, ok = st.categories[id]; !ok || c.UserID != userID {
+			c, err = models.Category{}, notFound("unable to retrieve category")
+		}
+	})
+
+	return c, err
+}
+
+// Create implements [store.CategoryStore].
+func (s *categoryStore) Create(ctx context.Context, c *models.Category) error {
+	return s.write(ctx, func(st *state) error {
+		initRecord(&c.ID, &c.CreatedAt)
+
+		if _, ok := st.users[c.UserID]; !ok {
+			return notFound("user of category does not exist")
+		}
+
+		if _, ok := st.categories[c.ID]; ok || categoryNameExists(st, *c) {
+			return conflict("unable to insert category")
+		}
+
+		st.categories[c.ID] = *c
+
+		return nil
+	})
+}
+
+// Update implements [store.CategoryStore].
+func (s *categoryStore) Update(ctx context.Context, c *models.Category) error {
+	return s.write(ctx, func(st *state) error {
+		cur, ok := st.categories[c.ID]
+		if !ok || cur.UserID != c.UserID {
+			return notFound("unable to update category")
+		}
+
+		// NOTE: Some fields are immutable.
+		if c.Name != "" {
+			cur.Name = c.Name
+		}
+		if !c.Description.IsZero() {
+			cur.Description = c.Description
+		}
+
+		if categoryNameExists(st, cur) {
+			return conflict("unable to update category")
+		}
+
+		st.categories[cur.ID] = cur
+		*c = cur
+
+		return nil
+	})
+}
+
+// Delete implements [store.CategoryStore].
+func (s *categoryStore) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return s.write(ctx, func(st *state) error {
+		if c, ok := st.categories[id]; !ok || c.UserID != userID {
+			return notFound("unable to delete category")
+		}
+
+		// the bookmarks reference the category without ON DELETE CASCADE
+		for _, b := range st.bookmarks {
+			if b.CategoryID == id {
+				return errors.New("unable to delete category: category is referenced by bookmarks")
+			}
+		}
+
+		delete(st.categories, id)
+
+		// ON DELETE SET NULL
+		for uID, u := range st.users {
+			if u.DefaultCategoryID.Valid && u.DefaultCategoryID.UUID == id {
+				u.DefaultCategoryID = uuid.NullUUID{}
+				st.users[uID] = u
+			}
+		}
+
+		return nil
+	})
+}
+
+// categoryNameExists returns true if another category of the user has the same name.
+func categoryNameExists(st *state, c models.Category) bool {
+	for _, v := range st.categories {
+		if v.ID != c.ID && v.UserID == c.UserID && v.Name == c.Name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,218 @@
+// Package memstore implements the stores in memory.
+// It is intended for tests and mirrors the constraints of the database schema.
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// txKey is the context key of the state of the current transaction.
+type txKey struct{}
+
+// state holds all records.
+type state struct {
+	users      map[uuid.UUID]models.UserAccount
+	categories map[uuid.UUID]models.Category
+	bookmarks  map[uuid.UUID]models.Bookmark
+	tags       map[uuid.UUID]models.Tag
+	// bookmarkTags maps the bookmark IDs to their tag IDs.
+	bookmarkTags map[uuid.UUID][]uuid.UUID
+}
+
+func newState() *state {
+	return &state{
+		users:        make(map[uuid.UUID]models.UserAccount),
+		categories:   make(map[uuid.UUID]models.Category),
+		bookmarks:    make(map[uuid.UUID]models.Bookmark),
+		tags:         make(map[uuid.UUID]models.Tag),
+		bookmarkTags: make(map[uuid.UUID][]uuid.UUID),
+	}
+}
+
+// clone returns a deep copy of the state.
+func (st *state) clone() *state {
+	c := newState()
+
+	for k, v := range st.users {
+		c.users[k] = v
+	}
+	for k, v := range st.categories {
+		c.categories[k] = v
+	}
+	for k, v := range st.bookmarks {
+		c.bookmarks[k] = v
+	}
+	for k, v := range st.tags {
+		c.tags[k] = v
+	}
+	for k, v := range st.bookmarkTags {
+		c.bookmarkTags[k] = append([]uuid.UUID(nil), v...)
+	}
+
+	return c
+}
+
+// db holds the committed state.
+type db struct {
+	mu    sync.RWMutex
+	state *state
+
+	// txMu serializes the transactions, so that a commit never
+	// overwrites the changes of another transaction.
+	txMu sync.Mutex
+}
+
+// New returns empty in-memory stores.
+func New() store.Store {
+	d := &db{state: newState()}
+
+	return store.Store{
+		Users:      &userStore{d},
+		Categories: &categoryStore{d},
+		Bookmarks:  &bookmarkStore{d},
+		Tags:       &tagStore{d},
+		UnitOfWork: d,
+	}
+}
+
+// RunInTx implements [store.UnitOfWork].
+// The transaction works on a copy of the state, which replaces the
+// state on commit.
+func (d *db) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*state); ok {
+		return fn(ctx)
+	}
+
+	d.txMu.Lock()
+	defer d.txMu.Unlock()
+
+	d.mu.RLock()
+	st := d.state.clone()
+	d.mu.RUnlock()
+
+	if err := fn(context.WithValue(ctx, txKey{}, st)); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.state = st
+	d.mu.Unlock()
+
+	return nil
+}
+
+// read calls fn with the state of the transaction of the context,
+// or the committed state if there is none.
+func (d *db) read(ctx context.Context, fn func(st *state)) {
+	if st, ok := ctx.Value(txKey{}).(*state); ok {
+		fn(st)
+		return
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	fn(d.state)
+}
+
+// write calls fn with the state of the transaction of the context.
+// If there is none, fn modifies a copy of the committed state,
+// which is only committed if fn succeeds.
+func (d *db) write(ctx context.Context, fn func(st *state) error) error {
+	if st, ok := ctx.Value(txKey{}).(*state); ok {
+		return fn(st)
+	}
+
+	return d.RunInTx(ctx, func(ctx context.Context) error {
+		return fn(ctx.Value(txKey{}).(*state))
+	})
+}
+
+// initRecord sets the ID and the creation time, if they are not set.
+func initRecord(id *uuid.UUID, createdAt *time.Time) {
+	if *id == uuid.Nil {
+		*id = uuid.New()
+	}
+
+	if createdAt.IsZero() {
+		*createdAt = time.Now().UTC()
+	}
+}
+
+// paginate returns the page of the records of the given user.
+func paginate[T any](records map[uuid.UUID]T, page store.Page, key func(T) (userID, id uuid.UUID, createdAt time.Time), userID uuid.UUID) []T {
+	ret := make([]T, 0, len(records))
+
+	for _, r := range records {
+		uid, id, createdAt := key(r)
+		if uid != userID {
+			continue
+		}
+
+		if !page.IsFirst() && !before(createdAt, id, page.AfterTime, page.AfterID) {
+			continue
+		}
+
+		ret = append(ret, r)
+	}
+
+	// newest first
+	sort.Slice(ret, func(i, j int) bool {
+		_, idI, tI := key(ret[i])
+		_, idJ, tJ := key(ret[j])
+
+		return before(tJ, idJ, tI, idI)
+	})
+
+	if len(ret) > page.Size() {
+		ret = ret[:page.Size()]
+	}
+
+	return ret
+}
+
+// before returns true if (t1, id1) < (t2, id2).
+func before(t1 time.Time, id1 uuid.UUID, t2 time.Time, id2 uuid.UUID) bool {
+	if !t1.Equal(t2) {
+		return t1.Before(t2)
+	}
+
+	return bytes.Compare(id1[:], id2[:]) < 0
+}
+
+func notFound(msg string) error {
+	return errors.Wrap(store.ErrNotFound, msg)
+}
+
+func conflict(msg string) error {
+	return errors.Wrap(store.ErrConflict, msg)
+}
+
+// blobRefs returns the distinct images, which reference a blob.
+func blobRefs(images []null.String) []string {
+	seen := make(map[string]struct{})
+	refs := []string{}
+
+	for _, img := range images {
+		if _, ok := seen[img.String]; ok || !img.Valid || !blob.IsRef(img.String) {
+			continue
+		}
+
+		seen[img.String] = struct{}{}
+		refs = append(refs, img.String)
+	}
+
+	return refs
+}
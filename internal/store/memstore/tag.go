@@ -0,0 +1,149 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+type tagStore struct {
+	*db
+}
+
+var _ store.TagStore = (*tagStore)(nil)
+
+func tagKey(t models.Tag) (uuid.UUID, uuid.UUID, time.Time) {
+	return t.UserID, t.ID, t.CreatedAt
+}
+
+// List implements [store.TagStore].
+func (s *tagStore) List(ctx context.Context, userID uuid.UUID, page store.Page) (ret []models.Tag, err error) {
+	s.read(ctx, func(st *state) {
+		ret = paginate(st.tags, page, tagKey, userID)
+	})
+
+	return ret, nil
+}
+
+// Get implements [store.TagStore].
+func (s *tagStore) Get(ctx context.Context, userID, id uuid.UUID) (t models.Tag, err error) {
+	s.read(ctx, func(st *state) {
+		var ok bool
+		if t, ok = st.tags[id]; !ok || t.UserID != userID {
+			t, err = models.Tag{}, notFound("unable to retrieve tag")
+		}
+	})
+
+	return t, err
+}
+
+// GetByName implements [store.TagStore].
+func (s *tagStore) GetByName(ctx context.Context, userID uuid.UUID, name string) (t models.Tag, err error) {
+	err = notFound("unable to retrieve tag")
+
+	s.read(ctx, func(st *state) {
+		for _, v := range st.tags {
+			if v.UserID == userID && v.Name == name {
+				t, err = v, nil
+				return
+			}
+		}
+	})
+
+	return t, err
+}
+
+// Create implements [store.TagStore].
+func (s *tagStore) Create(ctx context.Context, t *models.Tag) error {
+	return s.write(ctx, func(st *state) error {
+		initRecord(&t.ID, &t.CreatedAt)
+
+		if _, ok := st.users[t.UserID]; !ok {
+			return notFound("user of tag does not exist")
+		}
+
+		if _, ok := st.tags[t.ID]; ok || tagNameExists(st, *t) {
+			return conflict("unable to insert tag")
+		}
+
+		st.tags[t.ID] = *t
+
+		return nil
+	})
+}
+
+// Update implements [store.TagStore].
+func (s *tagStore) Update(ctx context.Context, t *models.Tag) error {
+	return s.write(ctx, func(st *state) error {
+		cur, ok := st.tags[t.ID]
+		if !ok || cur.UserID != t.UserID {
+			return notFound("unable to update tag")
+		}
+
+		// NOTE: Some fields are immutable.
+		if t.Name != "" {
+			cur.Name = t.Name
+		}
+		if !t.Description.IsZero() {
+			cur.Description = t.Description
+		}
+		if !t.Emoji.IsZero() {
+			cur.Emoji = t.Emoji
+		}
+
+		if tagNameExists(st, cur) {
+			return conflict("unable to update tag")
+		}
+
+		st.tags[cur.ID] = cur
+		*t = cur
+
+		return nil
+	})
+}
+
+// Delete implements [store.TagStore].
+func (s *tagStore) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return s.write(ctx, func(st *state) error {
+		if t, ok := st.tags[id]; !ok || t.UserID != userID {
+			return notFound("unable to delete tag")
+		}
+
+		delete(st.tags, id)
+
+		// ON DELETE CASCADE
+		for bID, tagIDs := range st.bookmarkTags {
+			st.bookmarkTags[bID] = without(tagIDs, id)
+		}
+
+		return nil
+	})
+}
+
+// tagNameExists returns true if another tag of the user has the same name.
+func tagNameExists(st *state, t models.Tag) bool {
+	for _, v := range st.tags {
+		if v.ID != t.ID && v.UserID == t.UserID && v.Name == t.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// without returns the IDs without the given ID.
+func without(ids []uuid.UUID, id uuid.UUID) []uuid.UUID {
+	ret := ids[:0]
+
+	for _, v := range ids {
+		if v != id {
+			ret = append(ret, v)
+		}
+	}
+
+	return ret
+}
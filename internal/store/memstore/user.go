@@ -0,0 +1,286 @@
+package memstore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+type userStore struct {
+	*db
+}
+
+var _ store.UserStore = (*userStore)(nil)
+
+// Get implements [store.UserStore].
+func (s *userStore) Get(ctx context.Context, id uuid.UUID) (u models.UserAccount, err error) {
+	s.read(ctx, func(st *state) {
+		var ok bool
+		if u, ok = st.users[id]; !ok {
+			err = notFound("unable to find user")
+		}
+	})
+
+	return u, err
+}
+
+// GetByEmail implements [store.UserStore].
+func (s *userStore) GetByEmail(ctx context.Context, email string) (u models.UserAccount, err error) {
+	err = notFound("unable to find user")
+
+	s.read(ctx, func(st *state) {
+		for _, v := range st.users {
+			if v.Email == email {
+				u, err = v, nil
+				return
+			}
+		}
+	})
+
+	return u, err
+}
+
+// Create implements [store.UserStore].
+func (s *userStore) Create(ctx context.Context, u *models.UserAccount) error {
+	return s.write(ctx, func(st *state) error {
+		initRecord(&u.ID, &u.CreatedAt)
+
+		if u.Role == "" {
+			u.Role = models.RoleUser
+		}
+
+		if _, ok := st.users[u.ID]; ok {
+			return conflict("user ID already exists")
+		}
+
+		for _, v := range st.users {
+			if v.Email == u.Email {
+				return conflict("user email already exists")
+			}
+		}
+
+		st.users[u.ID] = *u
+
+		return nil
+	})
+}
+
+// GetOrCreate implements [store.UserStore].
+func (s *userStore) GetOrCreate(ctx context.Context, u models.UserAccount) (models.UserAccount, error) {
+	ret, err := s.GetByEmail(ctx, u.Email)
+	if errors.Is(err, store.ErrNotFound) {
+		return u, s.Create(ctx, &u)
+	}
+
+	return ret, err
+}
+
+// List implements [store.UserStore].
+func (s *userStore) List(ctx context.Context, query string, page store.Page) (ret []models.UserAccount, err error) {
+	query = strings.ToLower(query)
+
+	s.read(ctx, func(st *state) {
+		users := make(map[uuid.UUID]models.UserAccount, len(st.users))
+		for id, u := range st.users {
+			if strings.Contains(strings.ToLower(u.Name), query) || strings.Contains(strings.ToLower(u.Email), query) {
+				users[id] = u
+			}
+		}
+
+		// the users are not owned by a user, they are all paginated under the nil ID
+		ret = paginate(users, page, func(u models.UserAccount) (uuid.UUID, uuid.UUID, time.Time) {
+			return uuid.Nil, u.ID, u.CreatedAt
+		}, uuid.Nil)
+	})
+
+	return ret, nil
+}
+
+// SetRole implements [store.UserStore].
+func (s *userStore) SetRole(ctx context.Context, id uuid.UUID, role string) error {
+	return s.write(ctx, func(st *state) error {
+		u, ok := st.users[id]
+		if !ok {
+			return notFound("unable to set user role")
+		}
+
+		u.Role = role
+		u.TokenGeneration++
+		st.users[id] = u
+
+		return nil
+	})
+}
+
+// RevokeTokens implements [store.UserStore].
+func (s *userStore) RevokeTokens(ctx context.Context, id uuid.UUID) error {
+	return s.write(ctx, func(st *state) error {
+		u, ok := st.users[id]
+		if !ok {
+			return notFound("unable to revoke user tokens")
+		}
+
+		u.TokenGeneration++
+		st.users[id] = u
+
+		return nil
+	})
+}
+
+// Counts implements [store.UserStore].
+func (s *userStore) Counts(ctx context.Context, id uuid.UUID) (c store.UserCounts, err error) {
+	s.read(ctx, func(st *state) {
+		if _, ok := st.users[id]; !ok {
+			err = notFound("unable to count user records")
+			return
+		}
+
+		for _, v := range st.categories {
+			if v.UserID == id {
+				c.Categories++
+			}
+		}
+		for _, v := range st.tags {
+			if v.UserID == id {
+				c.Tags++
+			}
+		}
+		for _, v := range st.bookmarks {
+			if v.UserID == id {
+				c.Bookmarks++
+			}
+		}
+	})
+
+	return c, err
+}
+
+// SetDisabled implements [store.UserStore].
+func (s *userStore) SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) error {
+	return s.write(ctx, func(st *state) error {
+		u, ok := st.users[id]
+		if !ok {
+			return notFound("unable to disable user")
+		}
+
+		switch {
+		case !disabled:
+			u.DisabledAt = null.Time{}
+		case !u.DisabledAt.Valid:
+			// keep the time of the first deactivation
+			u.DisabledAt = null.TimeFrom(time.Now().UTC())
+		}
+
+		st.users[id] = u
+
+		return nil
+	})
+}
+
+// UpdateProfile implements [store.UserStore].
+func (s *userStore) UpdateProfile(ctx context.Context, id uuid.UUID, p store.ProfileUpdate) (u models.UserAccount, err error) {
+	err = s.write(ctx, func(st *state) error {
+		var ok bool
+		if u, ok = st.users[id]; !ok {
+			return notFound("unable to update user profile")
+		}
+
+		if p.Name != nil {
+			u.Name = *p.Name
+		}
+		if p.Image != nil {
+			u.Image = *p.Image
+		}
+		if p.PreferredLanguage != nil {
+			u.PreferredLanguage = *p.PreferredLanguage
+		}
+		if p.Timezone != nil {
+			u.Timezone = *p.Timezone
+		}
+		if p.DefaultCategoryID != nil {
+			if _, ok := st.categories[p.DefaultCategoryID.UUID]; p.DefaultCategoryID.Valid && !ok {
+				return errors.Errorf("unable to update user profile: unknown category %s", p.DefaultCategoryID.UUID)
+			}
+
+			u.DefaultCategoryID = *p.DefaultCategoryID
+		}
+
+		st.users[id] = u
+
+		return nil
+	})
+
+	return u, err
+}
+
+// BlobRefs implements [store.UserStore].
+func (s *userStore) BlobRefs(ctx context.Context) (refs []string, err error) {
+	s.read(ctx, func(st *state) {
+		images := make([]null.String, 0, len(st.users))
+		for _, u := range st.users {
+			images = append(images, u.Image)
+		}
+
+		refs = blobRefs(images)
+	})
+
+	return refs, nil
+}
+
+// Sessions implements [store.UserStore].
+// Sessions are not stored in memory, the list is therefore always empty.
+func (s *userStore) Sessions(ctx context.Context, id uuid.UUID) (ret []models.Session, err error) {
+	s.read(ctx, func(st *state) {
+		if _, ok := st.users[id]; !ok {
+			err = notFound("unable to retrieve user sessions")
+		}
+	})
+
+	return ret, err
+}
+
+// Delete implements [store.UserStore].
+func (s *userStore) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.write(ctx, func(st *state) error {
+		if _, ok := st.users[id]; !ok {
+			return notFound("unable to delete user")
+		}
+
+		delete(st.users, id)
+
+		// ON DELETE CASCADE
+		for bID, b := range st.bookmarks {
+			if b.UserID == id {
+				delete(st.bookmarks, bID)
+				delete(st.bookmarkTags, bID)
+			}
+		}
+
+		for tID, t := range st.tags {
+			if t.UserID != id {
+				continue
+			}
+
+			delete(st.tags, tID)
+
+			for bID, tagIDs := range st.bookmarkTags {
+				st.bookmarkTags[bID] = without(tagIDs, tID)
+			}
+		}
+
+		for cID, c := range st.categories {
+			if c.UserID == id {
+				delete(st.categories, cID)
+			}
+		}
+
+		return nil
+	})
+}
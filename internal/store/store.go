@@ -0,0 +1,183 @@
+// Package store defines the interfaces to access the persisted data.
+//
+// The bun implementation is in package bunstore, an in-memory
+// implementation for tests in package memstore. Both must pass the
+// conformance tests of package storetest.
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+var (
+	// ErrNotFound is returned if the requested record does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrConflict is returned if a record violates a unique constraint,
+	// i.e. a tag with the same name already exists.
+	ErrConflict = errors.New("record already exists")
+)
+
+// DefaultPageSize is the page size used if no limit is given.
+const DefaultPageSize = 100
+
+// Page describes a page of records ordered by their creation time,
+// newest first.
+//
+// The zero value is the first page. For the following pages, the ID and
+// creation time of the last record of the previous page must be passed.
+type Page struct {
+	// AfterID is the ID of the last record of the previous page.
+	AfterID uuid.UUID
+	// AfterTime is the creation time of the last record of the previous page.
+	AfterTime time.Time
+	// Limit is the maximum number of records, [DefaultPageSize] if zero.
+	Limit int
+}
+
+// Size returns the maximum number of records of the page.
+func (p Page) Size() int {
+	if p.Limit <= 0 {
+		return DefaultPageSize
+	}
+
+	return p.Limit
+}
+
+// IsFirst returns true if the page is the first page.
+func (p Page) IsFirst() bool {
+	return p.AfterID == uuid.Nil || p.AfterTime.IsZero()
+}
+
+// Store bundles all stores of the application.
+type Store struct {
+	Users      UserStore
+	Categories CategoryStore
+	Bookmarks  BookmarkStore
+	Tags       TagStore
+
+	UnitOfWork
+}
+
+// UnitOfWork groups multiple store calls into one transaction.
+type UnitOfWork interface {
+	// RunInTx runs fn in a transaction.
+	//
+	// All store calls using the context passed to fn are part of the transaction.
+	// The transaction is committed if fn returns nil and rolled back otherwise.
+	// If ctx already carries a transaction, fn is part of that transaction.
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// UserStore stores the user accounts.
+type UserStore interface {
+	// Get returns the user with the given ID.
+	Get(ctx context.Context, id uuid.UUID) (models.UserAccount, error)
+	// GetByEmail returns the user with the given email address.
+	GetByEmail(ctx context.Context, email string) (models.UserAccount, error)
+	// Create inserts the user, the ID and creation time are set if they are empty.
+	Create(ctx context.Context, u *models.UserAccount) error
+	// GetOrCreate returns the user with the email address of u.
+	// If the user does not exist, it will be created.
+	GetOrCreate(ctx context.Context, u models.UserAccount) (models.UserAccount, error)
+	// List returns a page of all users. If query is not empty, only the users
+	// whose name or email address contains it (case-insensitive) are returned.
+	List(ctx context.Context, query string, page Page) ([]models.UserAccount, error)
+	// SetRole sets the role of the user.
+	// All tokens of the user are revoked, because they carry the old role.
+	SetRole(ctx context.Context, id uuid.UUID, role string) error
+	// RevokeTokens revokes all issued tokens of the user
+	// by incrementing the token generation.
+	RevokeTokens(ctx context.Context, id uuid.UUID) error
+	// Counts returns the number of records stored by the user.
+	Counts(ctx context.Context, id uuid.UUID) (UserCounts, error)
+	// SetDisabled disables or enables the user.
+	SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) error
+	// UpdateProfile updates the given profile fields and returns the updated user.
+	UpdateProfile(ctx context.Context, id uuid.UUID, p ProfileUpdate) (models.UserAccount, error)
+	// BlobRefs returns the images of all users, which reference a blob (see package blob).
+	BlobRefs(ctx context.Context) ([]string, error)
+	// Sessions returns all sessions of the user.
+	Sessions(ctx context.Context, id uuid.UUID) ([]models.Session, error)
+	// Delete deletes the user including all categories, tags, bookmarks and sessions.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// ProfileUpdate holds the profile fields of a user to update,
+// nil fields are not changed.
+type ProfileUpdate struct {
+	Name              *string
+	Image             *null.String
+	PreferredLanguage *null.String
+	Timezone          *null.String
+	DefaultCategoryID *uuid.NullUUID
+}
+
+// UserCounts is the number of records stored by a user.
+type UserCounts struct {
+	Categories int `json:"categories"`
+	Tags       int `json:"tags"`
+	Bookmarks  int `json:"bookmarks"`
+}
+
+// CategoryStore stores the categories of the users.
+type CategoryStore interface {
+	// List returns a page of categories of the user.
+	List(ctx context.Context, userID uuid.UUID, page Page) ([]models.Category, error)
+	// Get returns the category of the user with the given ID.
+	Get(ctx context.Context, userID, id uuid.UUID) (models.Category, error)
+	// Create inserts the category, the ID and creation time are set if they are empty.
+	Create(ctx context.Context, c *models.Category) error
+	// Update updates all non-empty mutable fields of the category.
+	Update(ctx context.Context, c *models.Category) error
+	// Delete deletes the category of the user with the given ID.
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+}
+
+// BookmarkStore stores the bookmarks of the users.
+type BookmarkStore interface {
+	// List returns a page of bookmarks of the user.
+	List(ctx context.Context, userID uuid.UUID, page Page) ([]models.Bookmark, error)
+	// Get returns the bookmark of the user with the given ID.
+	Get(ctx context.Context, userID, id uuid.UUID) (models.Bookmark, error)
+	// Create inserts the bookmark, the ID and creation time are set if they are empty.
+	Create(ctx context.Context, b *models.Bookmark) error
+	// Update updates all non-empty mutable fields of the bookmark.
+	Update(ctx context.Context, b *models.Bookmark) error
+	// Delete deletes the bookmark of the user with the given ID.
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// SetTags replaces the tags of the bookmark.
+	SetTags(ctx context.Context, bookmarkID uuid.UUID, tagIDs []uuid.UUID) error
+	// TagIDs returns the IDs of the tags of the bookmark.
+	TagIDs(ctx context.Context, bookmarkID uuid.UUID) ([]uuid.UUID, error)
+	// ListRemoteImages returns a page of bookmarks of all users,
+	// whose images are remote URLs instead of blob references.
+	ListRemoteImages(ctx context.Context, page Page) ([]models.Bookmark, error)
+	// ReplaceImage replaces the image of the bookmark, if it is still oldImage.
+	// It returns [ErrNotFound] if the image has been changed in the meantime.
+	ReplaceImage(ctx context.Context, id uuid.UUID, oldImage, newImage string) error
+	// BlobRefs returns the images of all bookmarks, which reference a blob (see package blob).
+	BlobRefs(ctx context.Context) ([]string, error)
+}
+
+// TagStore stores the tags of the users.
+type TagStore interface {
+	// List returns a page of tags of the user.
+	List(ctx context.Context, userID uuid.UUID, page Page) ([]models.Tag, error)
+	// Get returns the tag of the user with the given ID.
+	Get(ctx context.Context, userID, id uuid.UUID) (models.Tag, error)
+	// GetByName returns the tag of the user with the given name.
+	GetByName(ctx context.Context, userID uuid.UUID, name string) (models.Tag, error)
+	// Create inserts the tag, the ID and creation time are set if they are empty.
+	Create(ctx context.Context, t *models.Tag) error
+	// Update updates all non-empty mutable fields of the tag.
+	Update(ctx context.Context, t *models.Tag) error
+	// Delete deletes the tag of the user with the given ID.
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+}
@@ -0,0 +1,746 @@
+// Package storetest provides the conformance tests of the store implementations.
+//
+// Every implementation of [store.Store] must pass [Run], so that tests
+// using the in-memory stores behave like the application using the database.
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/blob"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// Run runs the conformance tests against the stores returned by newStore.
+// The stores may already hold records, all tests create their own users.
+func Run(t *testing.T, newStore func(t *testing.T) store.Store) {
+	tests := map[string]func(t *testing.T, s store.Store){
+		"users":        testUsers,
+		"categories":   testCategories,
+		"bookmarks":    testBookmarks,
+		"tags":         testTags,
+		"pagination":   testPagination,
+		"transactions": testTransactions,
+		"delete user":  testDeleteUser,
+		"blob refs":    testBlobRefs,
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test(t, newStore(t))
+		})
+	}
+}
+
+func testUsers(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	prefix := uuid.NewString()
+
+	u := models.UserAccount{Name: "Alice " + prefix, Email: prefix + "-alice@example.com"}
+	if err := s.Users.Create(ctx, &u); err != nil {
+		t.Fatal(err)
+	}
+
+	if u.ID == uuid.Nil || u.CreatedAt.IsZero() || u.Role != models.RoleUser {
+		t.Fatalf("expected the ID, creation time and default role to be set, got %+v", u)
+	}
+
+	dup := models.UserAccount{Name: "Duplicate", Email: u.Email}
+	if err := s.Users.Create(ctx, &dup); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("duplicate email: expected ErrConflict, got %v", err)
+	}
+
+	if got, err := s.Users.GetByEmail(ctx, u.Email); err != nil || got.ID != u.ID {
+		t.Errorf("expected user %s by email, got %s (%v)", u.ID, got.ID, err)
+	}
+
+	if _, err := s.Users.Get(ctx, uuid.New()); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("unknown user: expected ErrNotFound, got %v", err)
+	}
+
+	if _, err := s.Users.GetByEmail(ctx, prefix+"-unknown@example.com"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("unknown email: expected ErrNotFound, got %v", err)
+	}
+
+	got, err := s.Users.GetOrCreate(ctx, models.UserAccount{Name: "Other", Email: u.Email})
+	if err != nil || got.ID != u.ID || got.Name != u.Name {
+		t.Errorf("expected the existing user, got %+v (%v)", got, err)
+	}
+
+	bob, err := s.Users.GetOrCreate(ctx, models.UserAccount{Name: "Bob " + prefix, Email: prefix + "-bob@example.com"})
+	if err != nil || bob.ID == uuid.Nil {
+		t.Fatalf("expected a new user, got %+v (%v)", bob, err)
+	}
+
+	// the query matches the name and email address case-insensitively
+	list, err := s.Users.List(ctx, prefix, store.Page{})
+	if err != nil || len(list) != 2 || list[0].ID != bob.ID || list[1].ID != u.ID {
+		t.Errorf("expected bob and alice, got %+v (%v)", list, err)
+	}
+
+	if list, err := s.Users.List(ctx, "ALICE "+prefix, store.Page{}); err != nil || len(list) != 1 || list[0].ID != u.ID {
+		t.Errorf("expected alice, got %+v (%v)", list, err)
+	}
+
+	// the wildcards of the query are matched literally
+	if list, err := s.Users.List(ctx, prefix+"%", store.Page{}); err != nil || len(list) != 0 {
+		t.Errorf("expected no user, got %+v (%v)", list, err)
+	}
+
+	if err := s.Users.SetRole(ctx, u.ID, models.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Users.RevokeTokens(ctx, u.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := s.Users.Get(ctx, u.ID); err != nil || !got.IsAdmin() || got.TokenGeneration != u.TokenGeneration+2 {
+		t.Errorf("expected an admin with token generation %d, got %+v (%v)", u.TokenGeneration+2, got, err)
+	}
+
+	for name, err := range map[string]error{
+		"set role":      s.Users.SetRole(ctx, uuid.New(), models.RoleAdmin),
+		"revoke tokens": s.Users.RevokeTokens(ctx, uuid.New()),
+		"set disabled":  s.Users.SetDisabled(ctx, uuid.New(), true),
+		"delete":        s.Users.Delete(ctx, uuid.New()),
+	} {
+		if !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("%s of unknown user: expected ErrNotFound, got %v", name, err)
+		}
+	}
+
+	if err := s.Users.SetDisabled(ctx, u.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	disabled, err := s.Users.Get(ctx, u.ID)
+	if err != nil || !disabled.IsDisabled() {
+		t.Fatalf("expected a disabled user, got %+v (%v)", disabled, err)
+	}
+
+	// the time of the first deactivation is kept
+	if err := s.Users.SetDisabled(ctx, u.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := s.Users.Get(ctx, u.ID); err != nil || !got.DisabledAt.Time.Equal(disabled.DisabledAt.Time) {
+		t.Errorf("expected the disabled time %s, got %s (%v)", disabled.DisabledAt.Time, got.DisabledAt.Time, err)
+	}
+
+	if err := s.Users.SetDisabled(ctx, u.ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := s.Users.Get(ctx, u.ID); err != nil || got.IsDisabled() {
+		t.Errorf("expected an enabled user, got %+v (%v)", got, err)
+	}
+
+	category := models.Category{UserID: u.ID, Name: "Reading"}
+	if err := s.Categories.Create(ctx, &category); err != nil {
+		t.Fatal(err)
+	}
+
+	name := "Alice Liddell"
+	lang := null.StringFrom("de-DE")
+
+	updated, err := s.Users.UpdateProfile(ctx, u.ID, store.ProfileUpdate{
+		Name:              &name,
+		PreferredLanguage: &lang,
+		DefaultCategoryID: &uuid.NullUUID{UUID: category.ID, Valid: true},
+	})
+	if err != nil || updated.Name != name || updated.PreferredLanguage != lang || updated.DefaultCategoryID.UUID != category.ID || updated.Email != u.Email {
+		t.Fatalf("expected the updated profile, got %+v (%v)", updated, err)
+	}
+
+	// fields without a value are not changed
+	if got, err := s.Users.UpdateProfile(ctx, u.ID, store.ProfileUpdate{}); err != nil || got.Name != name || got.PreferredLanguage != lang {
+		t.Errorf("expected the unchanged profile, got %+v (%v)", got, err)
+	}
+
+	if _, err := s.Users.UpdateProfile(ctx, u.ID, store.ProfileUpdate{DefaultCategoryID: &uuid.NullUUID{UUID: uuid.New(), Valid: true}}); err == nil {
+		t.Error("expected an error for an unknown default category")
+	}
+
+	if _, err := s.Users.UpdateProfile(ctx, uuid.New(), store.ProfileUpdate{Name: &name}); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("profile of unknown user: expected ErrNotFound, got %v", err)
+	}
+
+	// the default category is reset, if it is deleted
+	if err := s.Categories.Delete(ctx, u.ID, category.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := s.Users.Get(ctx, u.ID); err != nil || got.DefaultCategoryID.Valid {
+		t.Errorf("expected no default category, got %+v (%v)", got.DefaultCategoryID, err)
+	}
+
+	if sessions, err := s.Users.Sessions(ctx, u.ID); err != nil || len(sessions) != 0 {
+		t.Errorf("expected no sessions, got %+v (%v)", sessions, err)
+	}
+}
+
+func testCategories(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	alice, bob := createUser(t, s), createUser(t, s)
+
+	c := models.Category{UserID: alice.ID, Name: "Reading", Description: null.StringFrom("Articles")}
+	if err := s.Categories.Create(ctx, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.ID == uuid.Nil || c.CreatedAt.IsZero() {
+		t.Fatalf("expected the ID and creation time to be set, got %+v", c)
+	}
+
+	if err := s.Categories.Create(ctx, &models.Category{UserID: alice.ID, Name: "Reading"}); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("duplicate name: expected ErrConflict, got %v", err)
+	}
+
+	// the names are unique per user
+	if err := s.Categories.Create(ctx, &models.Category{UserID: bob.ID, Name: "Reading"}); err != nil {
+		t.Errorf("same name of another user: %v", err)
+	}
+
+	if err := s.Categories.Create(ctx, &models.Category{UserID: uuid.New(), Name: "Orphan"}); err == nil {
+		t.Error("expected an error for an unknown user")
+	}
+
+	if got, err := s.Categories.Get(ctx, alice.ID, c.ID); err != nil || got.Name != c.Name || got.Description != c.Description {
+		t.Errorf("expected category %+v, got %+v (%v)", c, got, err)
+	}
+
+	// the categories of other users are not found
+	if _, err := s.Categories.Get(ctx, bob.ID, c.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("category of another user: expected ErrNotFound, got %v", err)
+	}
+
+	if err := s.Categories.Update(ctx, &models.Category{ID: c.ID, UserID: bob.ID, Name: "Stolen"}); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("update of another user: expected ErrNotFound, got %v", err)
+	}
+
+	// empty fields are not changed
+	update := models.Category{ID: c.ID, UserID: alice.ID, Name: "Later"}
+	if err := s.Categories.Update(ctx, &update); err != nil {
+		t.Fatal(err)
+	}
+
+	if update.Name != "Later" || update.Description != c.Description || !update.CreatedAt.Equal(c.CreatedAt) {
+		t.Errorf("expected the updated category, got %+v", update)
+	}
+
+	other := models.Category{UserID: alice.ID, Name: "Work"}
+	if err := s.Categories.Create(ctx, &other); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Categories.Update(ctx, &models.Category{ID: other.ID, UserID: alice.ID, Name: "Later"}); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("rename to an existing name: expected ErrConflict, got %v", err)
+	}
+
+	if list, err := s.Categories.List(ctx, alice.ID, store.Page{}); err != nil || len(list) != 2 {
+		t.Errorf("expected two categories, got %+v (%v)", list, err)
+	}
+
+	// categories with bookmarks can not be deleted
+	b := models.Bookmark{UserID: alice.ID, CategoryID: other.ID, URL: "https://example.com"}
+	if err := s.Bookmarks.Create(ctx, &b); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Categories.Delete(ctx, alice.ID, other.ID); err == nil {
+		t.Error("expected an error deleting a category with bookmarks")
+	}
+
+	if err := s.Categories.Delete(ctx, bob.ID, c.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("delete of another user: expected ErrNotFound, got %v", err)
+	}
+
+	if err := s.Categories.Delete(ctx, alice.ID, c.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Categories.Get(ctx, alice.ID, c.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("deleted category: expected ErrNotFound, got %v", err)
+	}
+}
+
+func testBookmarks(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	alice, bob := createUser(t, s), createUser(t, s)
+	category := createCategory(t, s, alice.ID)
+
+	b := models.Bookmark{UserID: alice.ID, CategoryID: category.ID, URL: "https://example.com", Description: null.StringFrom("Example")}
+	if err := s.Bookmarks.Create(ctx, &b); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.ID == uuid.Nil || b.CreatedAt.IsZero() {
+		t.Fatalf("expected the ID and creation time to be set, got %+v", b)
+	}
+
+	if err := s.Bookmarks.Create(ctx, &models.Bookmark{UserID: alice.ID, CategoryID: category.ID, URL: b.URL}); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("duplicate URL: expected ErrConflict, got %v", err)
+	}
+
+	if err := s.Bookmarks.Create(ctx, &models.Bookmark{UserID: alice.ID, CategoryID: uuid.New(), URL: "https://example.org"}); err == nil {
+		t.Error("expected an error for an unknown category")
+	}
+
+	if got, err := s.Bookmarks.Get(ctx, alice.ID, b.ID); err != nil || got.URL != b.URL || got.Description != b.Description {
+		t.Errorf("expected bookmark %+v, got %+v (%v)", b, got, err)
+	}
+
+	if _, err := s.Bookmarks.Get(ctx, bob.ID, b.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("bookmark of another user: expected ErrNotFound, got %v", err)
+	}
+
+	update := models.Bookmark{ID: b.ID, UserID: alice.ID, URL: "https://example.com/new"}
+	if err := s.Bookmarks.Update(ctx, &update); err != nil {
+		t.Fatal(err)
+	}
+
+	if update.URL != "https://example.com/new" || update.CategoryID != category.ID || update.Description != b.Description {
+		t.Errorf("expected the updated bookmark, got %+v", update)
+	}
+
+	if err := s.Bookmarks.Update(ctx, &models.Bookmark{ID: b.ID, UserID: bob.ID, URL: "https://example.com/stolen"}); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("update of another user: expected ErrNotFound, got %v", err)
+	}
+
+	tag1 := models.Tag{UserID: alice.ID, Name: "go"}
+	tag2 := models.Tag{UserID: alice.ID, Name: "web"}
+	for _, tag := range []*models.Tag{&tag1, &tag2} {
+		if err := s.Tags.Create(ctx, tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Bookmarks.SetTags(ctx, b.ID, []uuid.UUID{tag1.ID, tag2.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids, err := s.Bookmarks.TagIDs(ctx, b.ID); err != nil || !sameIDs(ids, []uuid.UUID{tag1.ID, tag2.ID}) {
+		t.Errorf("expected both tags, got %v (%v)", ids, err)
+	}
+
+	// the tags are replaced
+	if err := s.Bookmarks.SetTags(ctx, b.ID, []uuid.UUID{tag2.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids, err := s.Bookmarks.TagIDs(ctx, b.ID); err != nil || !sameIDs(ids, []uuid.UUID{tag2.ID}) {
+		t.Errorf("expected the second tag, got %v (%v)", ids, err)
+	}
+
+	// an unknown tag leaves the tags unchanged
+	if err := s.Bookmarks.SetTags(ctx, b.ID, []uuid.UUID{tag1.ID, uuid.New()}); err == nil {
+		t.Error("expected an error for an unknown tag")
+	}
+
+	if ids, err := s.Bookmarks.TagIDs(ctx, b.ID); err != nil || !sameIDs(ids, []uuid.UUID{tag2.ID}) {
+		t.Errorf("expected the unchanged tags, got %v (%v)", ids, err)
+	}
+
+	// deleting a tag removes it from the bookmarks
+	if err := s.Tags.Delete(ctx, alice.ID, tag2.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids, err := s.Bookmarks.TagIDs(ctx, b.ID); err != nil || len(ids) != 0 {
+		t.Errorf("expected no tags, got %v (%v)", ids, err)
+	}
+
+	if err := s.Bookmarks.Delete(ctx, bob.ID, b.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("delete of another user: expected ErrNotFound, got %v", err)
+	}
+
+	if err := s.Bookmarks.Delete(ctx, alice.ID, b.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Bookmarks.Get(ctx, alice.ID, b.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("deleted bookmark: expected ErrNotFound, got %v", err)
+	}
+
+	if err := s.Bookmarks.Delete(ctx, alice.ID, b.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("second delete: expected ErrNotFound, got %v", err)
+	}
+}
+
+func testTags(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	alice, bob := createUser(t, s), createUser(t, s)
+
+	tag := models.Tag{UserID: alice.ID, Name: "go", Emoji: null.StringFrom("🐹")}
+	if err := s.Tags.Create(ctx, &tag); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Tags.Create(ctx, &models.Tag{UserID: alice.ID, Name: "go"}); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("duplicate name: expected ErrConflict, got %v", err)
+	}
+
+	if err := s.Tags.Create(ctx, &models.Tag{UserID: bob.ID, Name: "go"}); err != nil {
+		t.Errorf("same name of another user: %v", err)
+	}
+
+	if got, err := s.Tags.GetByName(ctx, alice.ID, "go"); err != nil || got.ID != tag.ID || got.Emoji != tag.Emoji {
+		t.Errorf("expected tag %+v, got %+v (%v)", tag, got, err)
+	}
+
+	if _, err := s.Tags.GetByName(ctx, alice.ID, "rust"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("unknown name: expected ErrNotFound, got %v", err)
+	}
+
+	if _, err := s.Tags.Get(ctx, bob.ID, tag.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("tag of another user: expected ErrNotFound, got %v", err)
+	}
+
+	update := models.Tag{ID: tag.ID, UserID: alice.ID, Description: null.StringFrom("Gophers")}
+	if err := s.Tags.Update(ctx, &update); err != nil {
+		t.Fatal(err)
+	}
+
+	if update.Name != "go" || update.Emoji != tag.Emoji || update.Description.String != "Gophers" {
+		t.Errorf("expected the updated tag, got %+v", update)
+	}
+
+	web := models.Tag{UserID: alice.ID, Name: "web"}
+	if err := s.Tags.Create(ctx, &web); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Tags.Update(ctx, &models.Tag{ID: web.ID, UserID: alice.ID, Name: "go"}); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("rename to an existing name: expected ErrConflict, got %v", err)
+	}
+
+	if err := s.Tags.Delete(ctx, bob.ID, tag.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("delete of another user: expected ErrNotFound, got %v", err)
+	}
+
+	if err := s.Tags.Delete(ctx, alice.ID, tag.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if list, err := s.Tags.List(ctx, alice.ID, store.Page{}); err != nil || len(list) != 1 || list[0].ID != web.ID {
+		t.Errorf("expected the remaining tag, got %+v (%v)", list, err)
+	}
+}
+
+func testPagination(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	u := createUser(t, s)
+
+	// two tags share a creation time, they are ordered by their ID
+	created := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	times := []time.Time{created, created.Add(time.Minute), created.Add(time.Minute), created.Add(2 * time.Minute), created.Add(3 * time.Minute)}
+
+	for i, createdAt := range times {
+		if err := s.Tags.Create(ctx, &models.Tag{UserID: u.ID, Name: string(rune('a' + i)), CreatedAt: createdAt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := s.Tags.List(ctx, u.ID, store.Page{})
+	if err != nil || len(all) != len(times) {
+		t.Fatalf("expected %d tags, got %d (%v)", len(times), len(all), err)
+	}
+
+	for i := 1; i < len(all); i++ {
+		if all[i].CreatedAt.After(all[i-1].CreatedAt) {
+			t.Fatalf("expected the newest tag first, got %s after %s", all[i].CreatedAt, all[i-1].CreatedAt)
+		}
+	}
+
+	var (
+		page store.Page
+		got  []models.Tag
+	)
+
+	page.Limit = 2
+
+	for {
+		tags, err := s.Tags.List(ctx, u.ID, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(tags) == 0 {
+			break
+		}
+
+		if len(tags) > page.Limit {
+			t.Fatalf("expected at most %d tags, got %d", page.Limit, len(tags))
+		}
+
+		got = append(got, tags...)
+
+		last := tags[len(tags)-1]
+		page.AfterID, page.AfterTime = last.ID, last.CreatedAt
+	}
+
+	if len(got) != len(all) {
+		t.Fatalf("expected %d tags on all pages, got %d", len(all), len(got))
+	}
+
+	for i := range all {
+		if got[i].ID != all[i].ID {
+			t.Errorf("tag %d: expected %s, got %s", i, all[i].Name, got[i].Name)
+		}
+	}
+}
+
+func testTransactions(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	u := createUser(t, s)
+	errAbort := errors.New("abort")
+
+	var category models.Category
+
+	err := s.RunInTx(ctx, func(ctx context.Context) error {
+		category = models.Category{UserID: u.ID, Name: "Reading"}
+		if err := s.Categories.Create(ctx, &category); err != nil {
+			return err
+		}
+
+		// the changes are visible within the transaction
+		if _, err := s.Categories.Get(ctx, u.ID, category.ID); err != nil {
+			return err
+		}
+
+		// nested transactions are part of the outer transaction
+		return s.RunInTx(ctx, func(ctx context.Context) error {
+			if err := s.Tags.Create(ctx, &models.Tag{UserID: u.ID, Name: "go"}); err != nil {
+				return err
+			}
+
+			return errAbort
+		})
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("expected the error of the transaction, got %v", err)
+	}
+
+	if _, err := s.Categories.Get(ctx, u.ID, category.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected the category to be rolled back, got %v", err)
+	}
+
+	if _, err := s.Tags.GetByName(ctx, u.ID, "go"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected the tag to be rolled back, got %v", err)
+	}
+
+	err = s.RunInTx(ctx, func(ctx context.Context) error {
+		return s.Tags.Create(ctx, &models.Tag{UserID: u.ID, Name: "go"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Tags.GetByName(ctx, u.ID, "go"); err != nil {
+		t.Errorf("expected the tag to be committed, got %v", err)
+	}
+}
+
+func testDeleteUser(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	alice, bob := createUser(t, s), createUser(t, s)
+
+	category := createCategory(t, s, alice.ID)
+	tag := models.Tag{UserID: alice.ID, Name: "go"}
+	if err := s.Tags.Create(ctx, &tag); err != nil {
+		t.Fatal(err)
+	}
+
+	b := models.Bookmark{UserID: alice.ID, CategoryID: category.ID, URL: "https://example.com"}
+	if err := s.Bookmarks.Create(ctx, &b); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Bookmarks.SetTags(ctx, b.ID, []uuid.UUID{tag.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	bobCategory := createCategory(t, s, bob.ID)
+
+	if counts, err := s.Users.Counts(ctx, alice.ID); err != nil || counts != (store.UserCounts{Categories: 1, Tags: 1, Bookmarks: 1}) {
+		t.Errorf("expected one record of each type, got %+v (%v)", counts, err)
+	}
+
+	if err := s.Users.Delete(ctx, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Users.Get(ctx, alice.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("deleted user: expected ErrNotFound, got %v", err)
+	}
+
+	if _, err := s.Users.Counts(ctx, alice.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("counts of deleted user: expected ErrNotFound, got %v", err)
+	}
+
+	if list, err := s.Categories.List(ctx, alice.ID, store.Page{}); err != nil || len(list) != 0 {
+		t.Errorf("expected the categories to be deleted, got %+v (%v)", list, err)
+	}
+
+	if list, err := s.Bookmarks.List(ctx, alice.ID, store.Page{}); err != nil || len(list) != 0 {
+		t.Errorf("expected the bookmarks to be deleted, got %+v (%v)", list, err)
+	}
+
+	if ids, err := s.Bookmarks.TagIDs(ctx, b.ID); err != nil || len(ids) != 0 {
+		t.Errorf("expected the bookmark tags to be deleted, got %v (%v)", ids, err)
+	}
+
+	// the records of other users are kept
+	if _, err := s.Categories.Get(ctx, bob.ID, bobCategory.ID); err != nil {
+		t.Errorf("expected the category of another user, got %v", err)
+	}
+}
+
+func testBlobRefs(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	ref := blob.Ref(blob.Key([]byte(uuid.NewString()), ".png"))
+	other := blob.Ref(blob.Key([]byte(uuid.NewString()), ".png"))
+
+	u := createUser(t, s)
+	image := null.StringFrom(ref)
+
+	if _, err := s.Users.UpdateProfile(ctx, u.ID, store.ProfileUpdate{Image: &image}); err != nil {
+		t.Fatal(err)
+	}
+
+	category := createCategory(t, s, u.ID)
+	remote := models.Bookmark{UserID: u.ID, CategoryID: category.ID, URL: "https://example.com/remote", Image: null.StringFrom("https://example.com/image.png")}
+
+	for _, b := range []*models.Bookmark{
+		&remote,
+		{UserID: u.ID, CategoryID: category.ID, URL: "https://example.com/blob", Image: null.StringFrom(ref)},
+		{UserID: u.ID, CategoryID: category.ID, URL: "https://example.com/other", Image: null.StringFrom(other)},
+		{UserID: u.ID, CategoryID: category.ID, URL: "https://example.com/none"},
+	} {
+		if err := s.Bookmarks.Create(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if refs, err := s.Users.BlobRefs(ctx); err != nil || !contains(refs, ref) {
+		t.Errorf("expected the user blob reference, got %v (%v)", refs, err)
+	}
+
+	refs, err := s.Bookmarks.BlobRefs(ctx)
+	if err != nil || !contains(refs, ref) || !contains(refs, other) || contains(refs, remote.Image.String) {
+		t.Errorf("expected the bookmark blob references, got %v (%v)", refs, err)
+	}
+
+	// every reference is returned once
+	var n int
+	for _, r := range refs {
+		if r == ref {
+			n++
+		}
+	}
+
+	if n != 1 {
+		t.Errorf("expected the reference once, got it %d times", n)
+	}
+
+	var found bool
+
+	for page := (store.Page{}); ; {
+		list, err := s.Bookmarks.ListRemoteImages(ctx, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(list) == 0 {
+			break
+		}
+
+		for _, b := range list {
+			if b.Image.Valid && blob.IsRef(b.Image.String) {
+				t.Errorf("expected only remote images, got %s", b.Image.String)
+			}
+
+			found = found || b.ID == remote.ID
+		}
+
+		last := list[len(list)-1]
+		page.AfterID, page.AfterTime = last.ID, last.CreatedAt
+	}
+
+	if !found {
+		t.Error("expected the bookmark with the remote image")
+	}
+
+	if err := s.Bookmarks.ReplaceImage(ctx, remote.ID, remote.Image.String, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	// the image has been replaced in the meantime
+	if err := s.Bookmarks.ReplaceImage(ctx, remote.ID, remote.Image.String, other); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("changed image: expected ErrNotFound, got %v", err)
+	}
+
+	if got, err := s.Bookmarks.Get(ctx, u.ID, remote.ID); err != nil || got.Image.String != ref {
+		t.Errorf("expected the image %s, got %s (%v)", ref, got.Image.String, err)
+	}
+}
+
+// createUser creates a user with a unique email address.
+func createUser(t *testing.T, s store.Store) models.UserAccount {
+	t.Helper()
+
+	id := uuid.NewString()
+
+	u := models.UserAccount{Name: "User " + id, Email: id + "@example.com"}
+	if err := s.Users.Create(context.Background(), &u); err != nil {
+		t.Fatal(err)
+	}
+
+	return u
+}
+
+// createCategory creates a category of the user.
+func createCategory(t *testing.T, s store.Store, userID uuid.UUID) models.Category {
+	t.Helper()
+
+	c := models.Category{UserID: userID, Name: "Category " + uuid.NewString()}
+	if err := s.Categories.Create(context.Background(), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+// sameIDs returns true if both lists contain the same IDs, in any order.
+func sameIDs(a, b []uuid.UUID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, id := range a {
+		if !contains(b, id) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains[T comparable](list []T, v T) bool {
+	for _, e := range list {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
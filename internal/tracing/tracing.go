@@ -0,0 +1,176 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.uber.org/zap"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+)
+
+// Supported span exporters.
+const (
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterOTLPHTTP = "otlp-http"
+	ExporterStdout   = "stdout"
+)
+
+// Supported propagators.
+const (
+	PropagatorTraceContext = "tracecontext"
+	PropagatorBaggage      = "baggage"
+	PropagatorB3           = "b3"
+	PropagatorB3Multi      = "b3multi"
+)
+
+// ShutdownFunc flushes all pending spans and shuts down the tracer provider.
+type ShutdownFunc func(ctx context.Context) error
+
+// Init installs the global tracer provider and propagator based on the tracing configuration.
+//
+// If tracing is disabled, the no-op tracer provider is kept and the returned
+// ShutdownFunc does nothing. The propagator is always installed, so that
+// the trace context of callers is passed through.
+func Init(ctx context.Context) (ShutdownFunc, error) {
+	c := config.C.Load()
+	cfg := c.Debug.Tracing
+
+	prop, err := NewPropagator(cfg.Propagators)
+	if err != nil {
+		return nil, err
+	}
+
+	otel.SetTextMapPropagator(prop)
+
+	if !c.Debug.EnableTracing {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exp, err := newExporter(ctx, cfg.Exporter, cfg.Endpoint, cfg.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := newResource(ctx, cfg.ServiceName, cfg.ResourceAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.Warn("OpenTelemetry error", zap.Error(err))
+	}))
+
+	log.Info("Tracing enabled",
+		zap.String("exporter", cfg.Exporter),
+		zap.String("endpoint", cfg.Endpoint),
+		zap.Float64("sample_ratio", cfg.SampleRatio),
+	)
+
+	return func(ctx context.Context) error {
+		return errors.Wrap(tp.Shutdown(ctx), "unable to shutdown tracer provider")
+	}, nil
+}
+
+// NewPropagator returns a composite propagator of the given propagators.
+func NewPropagator(names []string) (propagation.TextMapPropagator, error) {
+	props := make([]propagation.TextMapPropagator, 0, len(names))
+
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case PropagatorTraceContext:
+			props = append(props, propagation.TraceContext{})
+		case PropagatorBaggage:
+			props = append(props, propagation.Baggage{})
+		case PropagatorB3:
+			props = append(props, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case PropagatorB3Multi:
+			props = append(props, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		default:
+			return nil, errors.Errorf("unknown propagator %q", name)
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(props...), nil
+}
+
+// newExporter returns the span exporter of the given type.
+func newExporter(ctx context.Context, exporter, endpoint string, insecure bool) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(exporter) {
+	case ExporterOTLPGRPC:
+		opts := []otlptracegrpc.Option{}
+		if endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+		}
+		if insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+
+		exp, err := otlptracegrpc.New(ctx, opts...)
+
+		return exp, errors.Wrap(err, "unable to create OTLP gRPC exporter")
+	case ExporterOTLPHTTP:
+		opts := []otlptracehttp.Option{}
+		if endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+		}
+		if insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		exp, err := otlptracehttp.New(ctx, opts...)
+
+		return exp, errors.Wrap(err, "unable to create OTLP HTTP exporter")
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+
+		return exp, errors.Wrap(err, "unable to create stdout exporter")
+	default:
+		return nil, errors.Errorf("unknown tracing exporter %q", exporter)
+	}
+}
+
+// newResource returns the resource describing this service.
+// The attributes must be in the "key=value" format.
+func newResource(ctx context.Context, serviceName string, attrs []string) (*resource.Resource, error) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs)+1)
+	kvs = append(kvs, semconv.ServiceNameKey.String(serviceName))
+
+	for _, attr := range attrs {
+		k, v, ok := strings.Cut(attr, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid resource attribute %q, expected key=value", attr)
+		}
+
+		kvs = append(kvs, attribute.String(strings.TrimSpace(k), strings.TrimSpace(v)))
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithAttributes(kvs...),
+	)
+
+	return res, errors.Wrap(err, "unable to create tracing resource")
+}
@@ -1,25 +1,41 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	_ "time/tzdata" // the time zones of the users must be available in minimal images
 
+	"github.com/fabmation-gmbh/briefkasten-go/handler"
 	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
 	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/tracing"
 	"github.com/fabmation-gmbh/briefkasten-go/migrations"
 	"github.com/fabmation-gmbh/briefkasten-go/models"
 
 	"github.com/uptrace/bun/migrate"
 	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
 )
 
-var migrator *migrate.Migrator
+var (
+	migrator *migrate.Migrator
+	// shutdownTracing flushes all pending spans.
+	shutdownTracing tracing.ShutdownFunc
+)
 
 func main() {
 	cmds := []*cli.Command{
+		newServerCommand(),
 		newDBCommand(),
+		newConfigCommand(),
+		newUserCommand(),
+		newBlobCommand(),
 	}
 	// cmds = append(cmds, cmd.NewCommands()...)
 
@@ -40,20 +56,17 @@ func main() {
 				Aliases: []string{"log-level", "l"},
 				Value:   "info",
 			},
+			&cli.StringFlag{
+				Name:  "listen",
+				Usage: "IP and port where the server should listen to",
+			},
 		},
-		Before: func(c *cli.Context) error {
-			return untilError(
-				func() error { return config.LoadConfig(c.String("config")) },
-				func() error {
-					log.InitLogging(c.String("level"), config.C.General.Environment)
-					return nil
-				},
-				func() error {
-					models.Connect()
-					migrator = migrate.NewMigrator(models.GetDB(), migrations.Migrations)
-					return nil
-				},
-			)
+		After: func(c *cli.Context) error {
+			if shutdownTracing == nil {
+				return nil
+			}
+
+			return shutdownTracing(c.Context)
 		},
 		Commands: cmds,
 	}
@@ -61,6 +74,48 @@ func main() {
 	fatalIf(app.Run(os.Args), "")
 }
 
+// setup loads the configuration and initializes the logging and tracing.
+func setup(c *cli.Context) error {
+	return untilError(
+		func() error { return config.LoadConfig(c.String("config"), flagOverrides(c)) },
+		func() error {
+			cfg := config.C.Load()
+			log.InitLogging(cfg.Log.Level, cfg.General.Environment)
+			return nil
+		},
+		func() (err error) {
+			shutdownTracing, err = tracing.Init(c.Context)
+			return err
+		},
+	)
+}
+
+// connectDB loads the configuration and connects to the database.
+func connectDB(c *cli.Context) error {
+	return untilError(
+		func() error { return setup(c) },
+		models.Connect,
+		func() error {
+			return models.WaitReady(c.Context, config.C.Load().DB.StartupTimeout)
+		},
+	)
+}
+
+// flagOverrides returns all explicitly set flags, which override configuration keys.
+func flagOverrides(c *cli.Context) map[string]any {
+	flags := make(map[string]any)
+
+	if c.IsSet("level") {
+		flags["log.level"] = c.String("level")
+	}
+
+	if c.IsSet("listen") {
+		flags["general.listen"] = c.String("listen")
+	}
+
+	return flags
+}
+
 func fatalIf(err error, msg string) {
 	if err == nil {
 		return
@@ -80,15 +135,98 @@ func untilError(f ...func() error) error {
 	return nil
 }
 
+func newServerCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "server",
+		Usage:  "start the HTTP server",
+		Before: setup,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "watch-config",
+				Usage: "reload the configuration file on change (it is always reloaded on SIGHUP)",
+				Value: true,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			// canceled on shutdown, so that a pending startup is aborted
+			ctx, cancel := context.WithCancel(c.Context)
+			defer cancel()
+
+			errCh := make(chan error, 1)
+			go func() { errCh <- handler.StartServer(ctx) }()
+
+			if c.Bool("watch-config") {
+				err := config.Watch(c.String("config"), func(err error) {
+					if err != nil {
+						log.Error("Configuration file is not watched anymore", zap.Error(err))
+						return
+					}
+
+					reloadConfig(c, "file:watch")
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			sigCh := make(chan os.Signal, 1)
+			signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+			defer signal.Stop(sigCh)
+
+		loop:
+			for {
+				select {
+				case err := <-errCh:
+					return err
+				case sig := <-sigCh:
+					if sig == syscall.SIGHUP {
+						reloadConfig(c, "signal:SIGHUP")
+						continue
+					}
+
+					log.Info("Received signal, starting graceful shutdown", zap.Stringer("signal", sig))
+					break loop
+				}
+			}
+
+			cancel()
+
+			if err := handler.Shutdown(config.C.Load().General.ShutdownTimeout); err != nil {
+				return err
+			}
+
+			return <-errCh
+		},
+	}
+}
+
+// reloadConfig reloads the configuration file and applies all settings
+// which can be changed without a restart.
+func reloadConfig(c *cli.Context, actor string) {
+	log.Info("Reloading configuration", zap.String("actor", actor))
+
+	if err := handler.ReloadConfig(c.Context, actor, c.String("config"), flagOverrides(c)); err != nil {
+		log.Error("Unable to reload configuration", zap.Error(err))
+	}
+}
+
 func newDBCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "db",
 		Usage: "database migrations",
 		Before: func(c *cli.Context) error {
-			_ = c
+			return untilError(
+				func() error { return connectDB(c) },
+				func() error {
+					ms, err := migrations.For(models.Dialect())
+					if err != nil {
+						return err
+					}
 
-			models.Connect()
-			return nil
+					migrator = migrate.NewMigrator(models.GetDB(), ms)
+					return nil
+				},
+			)
 		},
 		Subcommands: []*cli.Command{
 			{
@@ -101,7 +239,17 @@ func newDBCommand() *cli.Command {
 			{
 				Name:  "migrate",
 				Usage: "migrate database",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "print the SQL of all unapplied migrations without running them",
+					},
+				},
 				Action: func(c *cli.Context) error {
+					if c.Bool("dry-run") {
+						return printPendingSQL(c)
+					}
+
 					if err := migrator.Lock(c.Context); err != nil {
 						return err
 					}
@@ -156,10 +304,10 @@ func newDBCommand() *cli.Command {
 			},
 			{
 				Name:  "create_go",
-				Usage: "create Go migration",
+				Usage: "create Go migration, which is run for all dialects",
 				Action: func(c *cli.Context) error {
 					name := strings.Join(c.Args().Slice(), "_")
-					mf, err := migrator.CreateGoMigration(c.Context, name)
+					mf, err := migrate.NewMigrator(models.GetDB(), migrations.Migrations).CreateGoMigration(c.Context, name)
 					if err != nil {
 						return err
 					}
@@ -181,17 +329,33 @@ func newDBCommand() *cli.Command {
 						fmt.Printf("created migration %s (%s)\n", mf.Name, mf.Path)
 					}
 
+					fmt.Printf("NOTE: create the migration with the same name for all other dialects\n")
+
 					return nil
 				},
 			},
 			{
 				Name:  "status",
 				Usage: "print migrations status",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "print the status as JSON",
+					},
+				},
 				Action: func(c *cli.Context) error {
 					ms, err := migrator.MigrationsWithStatus(c.Context)
 					if err != nil {
 						return err
 					}
+
+					if c.Bool("json") {
+						enc := json.NewEncoder(os.Stdout)
+						enc.SetIndent("", "  ")
+
+						return enc.Encode(migrations.NewStatus(ms))
+					}
+
 					fmt.Printf("migrations: %s\n", ms)
 					fmt.Printf("unapplied migrations: %s\n", ms.Unapplied())
 					fmt.Printf("last migration group: %s\n", ms.LastGroup())
@@ -214,6 +378,8 @@ func newDBCommand() *cli.Command {
 					return nil
 				},
 			},
+			newDBSeedCommand(),
+			newDBResetCommand(),
 		},
 	}
 }
@@ -0,0 +1,165 @@
+package migrations
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun/migrate"
+)
+
+// lockRetryInterval is the interval between two attempts to acquire the migration lock.
+const lockRetryInterval = time.Second
+
+// Status is the machine-readable migration status.
+type Status struct {
+	// Migrations are all known migrations in ascending order.
+	Migrations []MigrationStatus `json:"migrations"`
+	// Unapplied are the names of all unapplied migrations.
+	Unapplied []string `json:"unapplied"`
+	// LastGroupID is the ID of the last applied migration group, zero if none was applied.
+	LastGroupID int64 `json:"last_group_id"`
+	// UpToDate reports whether all migrations are applied.
+	UpToDate bool `json:"up_to_date"`
+}
+
+// MigrationStatus is the status of a single migration.
+type MigrationStatus struct {
+	Name       string     `json:"name"`
+	Comment    string     `json:"comment"`
+	Applied    bool       `json:"applied"`
+	GroupID    int64      `json:"group_id,omitempty"`
+	MigratedAt *time.Time `json:"migrated_at,omitempty"`
+}
+
+// NewStatus returns the status of the migrations returned by
+// [migrate.Migrator.MigrationsWithStatus].
+func NewStatus(ms migrate.MigrationSlice) Status {
+	s := Status{
+		Migrations:  make([]MigrationStatus, 0, len(ms)),
+		Unapplied:   []string{},
+		LastGroupID: ms.LastGroupID(),
+	}
+
+	for _, m := range ms {
+		st := MigrationStatus{
+			Name:    m.Name,
+			Comment: m.Comment,
+			Applied: m.IsApplied(),
+			GroupID: m.GroupID,
+		}
+
+		if st.Applied {
+			at := m.MigratedAt
+			st.MigratedAt = &at
+		} else {
+			s.Unapplied = append(s.Unapplied, m.String())
+		}
+
+		s.Migrations = append(s.Migrations, st)
+	}
+
+	s.UpToDate = len(s.Unapplied) == 0
+
+	return s
+}
+
+// UpSQL returns the SQL of the up migration of the given dialect.
+func UpSQL(dialect string, m migrate.Migration) (string, error) {
+	for _, suffix := range []string{".up.sql", ".tx.up.sql"} {
+		data, err := migFS.ReadFile(path.Join(dialect, m.String()+suffix))
+		if err == nil {
+			return string(data), nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", errors.Wrapf(err, "unable to read migration %s", m)
+		}
+	}
+
+	return "", errors.Errorf("no SQL file for migration %s of dialect %q", m, dialect)
+}
+
+// EnsureApplied returns an error if there are unapplied migrations.
+//
+// If autoMigrate is true, the migrations are applied instead. The migration
+// lock is acquired first, so that multiple instances can start concurrently.
+// The lock is retried until the context is done.
+func EnsureApplied(ctx context.Context, m *migrate.Migrator, autoMigrate bool) (*migrate.MigrationGroup, error) {
+	if !autoMigrate {
+		ms, err := m.MigrationsWithStatus(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to retrieve migration status (run `db init` and `db migrate`)")
+		}
+
+		if unapplied := ms.Unapplied(); len(unapplied) > 0 {
+			return nil, errors.Errorf("database schema is outdated, %d unapplied migration(s): %s "+
+				"(run `db migrate` or enable db.auto_migrate)", len(unapplied), unapplied)
+		}
+
+		return nil, nil
+	}
+
+	if err := m.Init(ctx); err != nil {
+		return nil, errors.Wrap(err, "unable to create migration tables")
+	}
+
+	if err := lock(ctx, m); err != nil {
+		return nil, err
+	}
+	defer m.Unlock(context.Background()) //nolint:errcheck
+
+	// Migrate only runs the migrations, which are still unapplied
+	// after another instance released the lock.
+	group, err := m.Migrate(ctx)
+
+	return group, errors.Wrap(err, "unable to migrate database")
+}
+
+// lock acquires the migration lock, it retries until the context is done.
+func lock(ctx context.Context, m *migrate.Migrator) error {
+	t := time.NewTicker(lockRetryInterval)
+	defer t.Stop()
+
+	for {
+		err := m.Lock(ctx)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(err, "unable to acquire migration lock")
+		case <-t.C:
+		}
+	}
+}
+
+// checkDownFiles verifies that every up migration has a down migration.
+func checkDownFiles(fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		known[f] = struct{}{}
+	}
+
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".up.sql") {
+			continue
+		}
+
+		down := strings.TrimSuffix(f, ".up.sql") + ".down.sql"
+		if _, ok := known[down]; !ok {
+			return errors.Errorf("migration %s has no down migration %s", f, down)
+		}
+	}
+
+	return nil
+}
@@ -2,21 +2,94 @@ package migrations
 
 import (
 	"embed"
+	"io/fs"
+	"path/filepath"
+	"runtime"
 
+	"github.com/pkg/errors"
 	"github.com/uptrace/bun/migrate"
 )
 
-// Migrations holds all registered SQL migrations.
+// Supported dialects, the SQL migrations of each dialect are
+// stored in the directory of the same name.
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// Migrations holds the Go migrations, which are run for all dialects.
+// They are created with the `db create_go` command and registered by
+// the init function of their file.
 var Migrations = migrate.NewMigrations()
 
-//go:embed *.sql
+// sqlMigrations holds the discovered SQL migrations of all dialects.
+var sqlMigrations = map[string]*migrate.Migrations{
+	DialectPostgres: discoverSQL(DialectPostgres),
+	DialectSQLite:   discoverSQL(DialectSQLite),
+}
+
+//go:embed postgres/*.sql sqlite/*.sql
 var migFS embed.FS
 
-func init() {
-	if err := Migrations.Discover(migFS); err != nil {
+// For returns the SQL migrations of the given dialect and all Go migrations.
+//
+// NOTE: Every SQL migration must exist for all dialects with the same name
+// and must have a down migration.
+func For(dialect string) (*migrate.Migrations, error) {
+	sql, ok := sqlMigrations[dialect]
+	if !ok {
+		return nil, errors.Errorf("no migrations for dialect %q", dialect)
+	}
+
+	// the Go migrations are registered after the SQL migrations have been
+	// discovered, they are therefore merged on demand
+	m := migrate.NewMigrations(migrate.WithMigrationsDirectory(dialectDir(dialect)))
+
+	for _, ms := range []*migrate.Migrations{sql, Migrations} {
+		for _, mig := range ms.Sorted() {
+			m.Add(mig)
+		}
+	}
+
+	return m, nil
+}
+
+// IsGo returns true if m is a Go migration, see [Migrations].
+func IsGo(m migrate.Migration) bool {
+	for _, mig := range Migrations.Sorted() {
+		if mig.Name == m.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// discoverSQL discovers the SQL migrations of the dialect.
+func discoverSQL(dialect string) *migrate.Migrations {
+	m := migrate.NewMigrations()
+
+	sub, err := fs.Sub(migFS, dialect)
+	if err != nil {
 		panic(err)
 	}
-	// if err := Migrations.DiscoverCaller(); err != nil {
-	//     panic(err)
-	// }
+
+	// every migration must be revertible
+	if err := checkDownFiles(sub); err != nil {
+		panic(err)
+	}
+
+	if err := m.Discover(sub); err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+// dialectDir returns the directory of the SQL migrations of the dialect.
+// New SQL migrations are created in it.
+func dialectDir(dialect string) string {
+	_, file, _, _ := runtime.Caller(0)
+
+	return filepath.Join(filepath.Dir(file), dialect)
 }
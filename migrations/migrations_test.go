@@ -0,0 +1,89 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+func TestDialectsHaveSameMigrations(t *testing.T) {
+	pg, err := For(DialectPostgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqlite, err := For(DialectSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, got := pg.Sorted(), sqlite.Sorted()
+	if len(want) != len(got) {
+		t.Fatalf("expected %d SQLite migrations, got %d", len(want), len(got))
+	}
+
+	for i := range want {
+		if want[i].String() != got[i].String() {
+			t.Errorf("migration %d: expected %s for SQLite, got %s", i, want[i], got[i])
+		}
+
+		if IsGo(want[i]) {
+			continue
+		}
+
+		for _, dialect := range []string{DialectPostgres, DialectSQLite} {
+			if _, err := UpSQL(dialect, want[i]); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
+
+func TestGoMigrations(t *testing.T) {
+	noop := func(context.Context, *bun.DB) error { return nil }
+	Migrations.Add(migrate.Migration{Name: "29991231000000", Comment: "go_test", Up: noop, Down: noop})
+
+	for _, dialect := range []string{DialectPostgres, DialectSQLite} {
+		ms, err := For(dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sorted := ms.Sorted()
+		if last := sorted[len(sorted)-1]; last.Name != "29991231000000" || !IsGo(last) {
+			t.Errorf("%s: expected the Go migration to be the last migration, got %s", dialect, last)
+		}
+
+		if IsGo(sorted[0]) {
+			t.Errorf("%s: expected the SQL migration %s not to be a Go migration", dialect, sorted[0])
+		}
+	}
+}
+
+func TestCheckDownFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		wantErr bool
+	}{
+		{name: "up and down", files: []string{"1_a.up.sql", "1_a.down.sql"}},
+		{name: "tx up and down", files: []string{"1_a.tx.up.sql", "1_a.tx.down.sql"}},
+		{name: "missing down", files: []string{"1_a.up.sql", "2_b.up.sql", "2_b.down.sql"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for _, f := range tt.files {
+				fsys[f] = &fstest.MapFile{}
+			}
+
+			if err := checkDownFiles(fsys); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %t, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+DROP TABLE IF EXISTS session;
+
+--bun:split
+
+DROP TABLE IF EXISTS tag_on_bookmark;
+
+--bun:split
+
+DROP TABLE IF EXISTS tag;
+
+--bun:split
+
+DROP TABLE IF EXISTS bookmark;
+
+--bun:split
+
+DROP TABLE IF EXISTS category;
+
+--bun:split
+
+DROP TABLE IF EXISTS user_account;
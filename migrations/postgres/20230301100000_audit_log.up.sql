@@ -0,0 +1,11 @@
+CREATE TABLE audit_log (
+  id UUID NOT NULL PRIMARY KEY UNIQUE DEFAULT uuid_generate_v4(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  actor TEXT NOT NULL,
+  action TEXT NOT NULL,
+  old_value TEXT,
+  new_value TEXT
+);
+
+CREATE INDEX audit_log_on_created_at
+  ON audit_log (created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE user_account
+  ADD COLUMN token_generation BIGINT NOT NULL DEFAULT 0;
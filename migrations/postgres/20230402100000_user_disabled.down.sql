@@ -0,0 +1 @@
+ALTER TABLE user_account DROP COLUMN IF EXISTS disabled_at;
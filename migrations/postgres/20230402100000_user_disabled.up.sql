@@ -0,0 +1,2 @@
+ALTER TABLE user_account
+  ADD COLUMN disabled_at TIMESTAMPTZ;
@@ -0,0 +1,9 @@
+ALTER TABLE user_account DROP COLUMN IF EXISTS default_category_id;
+
+--bun:split
+
+ALTER TABLE user_account DROP COLUMN IF EXISTS timezone;
+
+--bun:split
+
+ALTER TABLE user_account DROP COLUMN IF EXISTS preferred_language;
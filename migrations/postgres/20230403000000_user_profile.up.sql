@@ -0,0 +1,10 @@
+ALTER TABLE user_account ADD COLUMN preferred_language TEXT;
+
+--bun:split
+
+ALTER TABLE user_account ADD COLUMN timezone TEXT;
+
+--bun:split
+
+ALTER TABLE user_account
+  ADD COLUMN default_category_id UUID REFERENCES category (id) ON DELETE SET NULL;
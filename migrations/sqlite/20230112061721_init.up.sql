@@ -0,0 +1,87 @@
+-- NOTE: SQLite has no UUID type, the IDs are generated by the application
+-- and stored as text.
+
+CREATE TABLE user_account (
+  id TEXT NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  name TEXT NOT NULL,
+  email TEXT NOT NULL,
+  email_verified BOOLEAN NOT NULL DEFAULT false,
+  image TEXT
+);
+
+--bun:split
+
+CREATE UNIQUE INDEX unique_user_on_email
+  ON user_account (email);
+
+--bun:split
+
+CREATE TABLE category (
+  id TEXT NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  user_id TEXT NOT NULL REFERENCES user_account (id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  description TEXT
+);
+
+--bun:split
+
+CREATE UNIQUE INDEX unique_category_on_user_id_and_name
+  ON category (user_id, name);
+
+--bun:split
+
+CREATE TABLE bookmark (
+  id TEXT NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  user_id TEXT NOT NULL REFERENCES user_account (id) ON DELETE CASCADE,
+  category_id TEXT NOT NULL REFERENCES category (id),
+  url TEXT NOT NULL,
+  image TEXT,
+  description TEXT
+);
+
+--bun:split
+
+CREATE UNIQUE INDEX unique_bookmark_on_user_id_and_url
+  ON bookmark (user_id, url);
+
+--bun:split
+
+CREATE TABLE tag (
+  id TEXT NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  user_id TEXT NOT NULL REFERENCES user_account (id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  description TEXT,
+  emoji TEXT
+);
+
+--bun:split
+
+CREATE UNIQUE INDEX unique_tag_on_user_id_and_name
+  ON tag (user_id, name);
+
+--bun:split
+
+CREATE TABLE tag_on_bookmark (
+  bookmark_id TEXT NOT NULL REFERENCES bookmark (id) ON DELETE CASCADE,
+  tag_id TEXT NOT NULL REFERENCES tag (id) ON DELETE CASCADE,
+
+  constraint pk_tag_on_bookmark
+    PRIMARY KEY (bookmark_id, tag_id)
+);
+
+--bun:split
+
+-- NOTE: We may move this table completely to redis!
+CREATE TABLE session (
+  token TEXT NOT NULL,
+  user_id TEXT NOT NULL REFERENCES user_account (id) ON DELETE CASCADE,
+  expires TIMESTAMP NOT NULL,
+  raw_data TEXT NOT NULL,
+
+  constraint pk_session
+    PRIMARY KEY (token, user_id)
+);
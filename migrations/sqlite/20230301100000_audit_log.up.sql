@@ -0,0 +1,13 @@
+CREATE TABLE audit_log (
+  id TEXT NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  actor TEXT NOT NULL,
+  action TEXT NOT NULL,
+  old_value TEXT,
+  new_value TEXT
+);
+
+--bun:split
+
+CREATE INDEX audit_log_on_created_at
+  ON audit_log (created_at);
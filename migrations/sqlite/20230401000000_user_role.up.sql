@@ -0,0 +1,3 @@
+ALTER TABLE user_account
+  ADD COLUMN role TEXT NOT NULL DEFAULT 'user'
+  CHECK (role IN ('user', 'admin'));
@@ -0,0 +1,2 @@
+ALTER TABLE user_account
+  ADD COLUMN token_generation INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+ALTER TABLE user_account DROP COLUMN default_category_id;
+
+--bun:split
+
+ALTER TABLE user_account DROP COLUMN timezone;
+
+--bun:split
+
+ALTER TABLE user_account DROP COLUMN preferred_language;
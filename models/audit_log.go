@@ -0,0 +1,32 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+	"gopkg.in/guregu/null.v4"
+)
+
+type AuditLog struct {
+	bun.BaseModel `bun:"audit_log"`
+
+	ID        uuid.UUID   `bun:"id,nullzero" json:"id"`
+	CreatedAt time.Time   `bun:"created_at,nullzero" json:"created_at"`
+	Actor     string      `bun:"actor" json:"actor"`
+	Action    string      `bun:"action" json:"action"`
+	OldValue  null.String `bun:"old_value" json:"old_value"`
+	NewValue  null.String `bun:"new_value" json:"new_value"`
+}
+
+// Create inserts the object into the table.
+func (a *AuditLog) Create(ctx context.Context) error {
+	_, err := IDB(ctx).NewInsert().
+		Model(a).
+		Returning("*").
+		Exec(ctx)
+
+	return errors.Wrap(err, "unable to insert audit log entry into DB")
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+var (
+	_ bun.BeforeAppendModelHook = (*UserAccount)(nil)
+	_ bun.BeforeAppendModelHook = (*Category)(nil)
+	_ bun.BeforeAppendModelHook = (*Bookmark)(nil)
+	_ bun.BeforeAppendModelHook = (*Tag)(nil)
+	_ bun.BeforeAppendModelHook = (*AuditLog)(nil)
+)
+
+// initRecord sets the ID and the creation time of a record, if they are not set
+// and the record is inserted.
+// The database defaults are not used, because SQLite is unable to generate UUIDs.
+func initRecord(query bun.Query, id *uuid.UUID, createdAt *time.Time) {
+	if _, ok := query.(*bun.InsertQuery); !ok {
+		return
+	}
+
+	if *id == uuid.Nil {
+		*id = uuid.New()
+	}
+
+	if createdAt.IsZero() {
+		*createdAt = time.Now().UTC()
+	}
+}
+
+// BeforeAppendModel implements [bun.BeforeAppendModelHook].
+func (u *UserAccount) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	initRecord(query, &u.ID, &u.CreatedAt)
+
+	if _, ok := query.(*bun.InsertQuery); ok && u.Role == "" {
+		u.Role = RoleUser
+	}
+
+	return nil
+}
+
+// BeforeAppendModel implements [bun.BeforeAppendModelHook].
+func (c *Category) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	initRecord(query, &c.ID, &c.CreatedAt)
+	return nil
+}
+
+// BeforeAppendModel implements [bun.BeforeAppendModelHook].
+func (b *Bookmark) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	initRecord(query, &b.ID, &b.CreatedAt)
+	return nil
+}
+
+// BeforeAppendModel implements [bun.BeforeAppendModelHook].
+func (t *Tag) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	initRecord(query, &t.ID, &t.CreatedAt)
+	return nil
+}
+
+// BeforeAppendModel implements [bun.BeforeAppendModelHook].
+func (a *AuditLog) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	initRecord(query, &a.ID, &a.CreatedAt)
+	return nil
+}
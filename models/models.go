@@ -2,20 +2,21 @@ package models
 
 import (
 	"context"
-	"crypto/tls"
 	"database/sql"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/log"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/metrics"
 
 	"github.com/pkg/errors"
 	"github.com/uptrace/bun"
-	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
-	"github.com/uptrace/bun/extra/bundebug"
 	"github.com/uptrace/bun/extra/bunotel"
+	"go.uber.org/zap"
 )
 
 var db *bun.DB
@@ -26,65 +27,110 @@ func GetDB() *bun.DB {
 	return db
 }
 
-// Connect tries to open the SQLite DB and if it does not exist, it will create a new one.
-func Connect() {
-	dsn := os.Getenv("DB_CONNECTION")
-	if dsn == "" {
-		dsn = config.C.DB.URI
-	}
+// Supported database dialects, selected by the scheme of the connection URI.
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
 
-	opts := []pgdriver.Option{
-		pgdriver.WithDSN(dsn),
-	}
+// dialect is the dialect of the current connection.
+var dialect string
 
-	if strings.HasSuffix(dsn, "require") {
-		opts = append(opts, pgdriver.WithTLSConfig(&tls.Config{InsecureSkipVerify: true}))
+// Connect opens the database connection pool.
+// The connections are established lazily, use [WaitReady] to
+// wait until the database is reachable.
+//
+// The dialect is selected by the scheme of the connection URI,
+// "sqlite:" selects SQLite, all other schemes select Postgres.
+func Connect() error {
+	cfg := config.C.Load()
+
+	var err error
+
+	dialect = DialectOf(cfg.DB.URI)
+	switch dialect {
+	case DialectSQLite:
+		db, err = openSQLite(cfg)
+	default:
+		db, err = openPostgres(cfg)
 	}
 
-	sqlDB := sql.OpenDB(pgdriver.NewConnector(
-		opts...,
-	))
+	if err != nil {
+		return err
+	}
 
-	db = bun.NewDB(sqlDB, pgdialect.New())
+	// the SQL debug output can be toggled at runtime
+	sqlDebug.Store(cfg.Debug.EnableSQLDebug)
+	db.AddQueryHook(newSQLDebugHook())
 
-	if config.C.Debug.EnableSQLDebug {
-		db.AddQueryHook(bundebug.NewQueryHook(bundebug.WithVerbose(true)))
+	if cfg.Metrics.Enabled {
+		db.AddQueryHook(metrics.NewQueryHook())
 	}
 
-	if config.C.Debug.EnableTracing {
+	if cfg.Debug.EnableTracing {
 		db.AddQueryHook(bunotel.NewQueryHook(
-			bunotel.WithDBName("nextcloud-vfs"),
+			bunotel.WithDBName(cfg.Debug.Tracing.DBName),
 			bunotel.WithFormattedQueries(true),
 		))
 	}
-}
 
-// newNullInt64 returns a valid and initialized sql.NullInt64,
-func newNullInt64(n int64) sql.NullInt64 {
-	return sql.NullInt64{
-		Valid: true,
-		Int64: n,
-	}
+	return nil
 }
 
-// StartTx starts a transaction with the given options.
-func StartTx(ctx context.Context, opts *sql.TxOptions) (bun.Tx, error) {
-	tx, err := db.BeginTx(ctx, opts)
-	if err != nil {
-		return bun.Tx{}, errors.Wrap(err, "unable to start transaction")
+// DialectOf returns the dialect of the connection URI.
+func DialectOf(uri string) string {
+	if strings.HasPrefix(uri, "sqlite:") {
+		return DialectSQLite
 	}
 
-	return tx, nil
+	return DialectPostgres
+}
+
+// Dialect returns the dialect of the current connection.
+func Dialect() string {
+	return dialect
 }
 
-// getDB returns the base DB connection or the given
-// tx.
-func getDB(dbs ...bun.IDB) bun.IDB {
-	if len(dbs) == 0 {
-		return db
+// WaitReady pings the database until it is reachable or the timeout is exceeded.
+// The delay between the attempts is doubled after every attempt, up to 10 seconds.
+func WaitReady(ctx context.Context, timeout time.Duration) error {
+	const maxDelay = 10 * time.Second
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	delay := 250 * time.Millisecond
+
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+
+		log.Warn("Database is not reachable, retrying",
+			zap.Error(err),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+		)
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(err, "database not reachable after %d attempts", attempt)
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > maxDelay {
+			delay = maxDelay
+		}
 	}
+}
 
-	return dbs[0]
+// newNullInt64 returns a valid and initialized sql.NullInt64,
+func newNullInt64(n int64) sql.NullInt64 {
+	return sql.NullInt64{
+		Valid: true,
+		Int64: n,
+	}
 }
 
 func getBoolEnv(name string) bool {
@@ -105,18 +151,19 @@ func IsNoRows(err error) bool {
 // a unique constraint violation.
 //
 // See https://www.postgresql.org/docs/current/errcodes-appendix.html
+// and https://www.sqlite.org/rescode.html#constraint_unique
 func IsUniqueViolationErr(err error) bool {
-	e, ok := getPsqlError(err)
-	if !ok {
-		return false
+	if e, ok := getPsqlError(err); ok {
+		return e.Field('C') == "23505"
 	}
 
-	return e.Field('C') == "23505"
+	// the SQLite error depends on the driver, but the message is always the same
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
 }
 
 func getPsqlError(err error) (pgdriver.Error, bool) {
-	pg, ok := err.(pgdriver.Error)
-	if !ok {
+	var pg pgdriver.Error
+	if !errors.As(err, &pg) {
 		return pgdriver.Error{}, false
 	}
 
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/driver/pgdriver"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+// openPostgres opens the Postgres connection pool.
+func openPostgres(cfg *config.Config) (*bun.DB, error) {
+	opts := []pgdriver.Option{
+		pgdriver.WithDSN(cfg.DB.URI),
+		pgdriver.WithDialTimeout(cfg.DB.DialTimeout),
+		pgdriver.WithReadTimeout(cfg.DB.ReadTimeout),
+		withSessionTimeouts(cfg.DB.StatementTimeout, cfg.DB.LockTimeout),
+	}
+
+	// the sslmode of the URI is used, if no mode is configured
+	if cfg.DB.TLS.Mode != "" {
+		tlsCfg, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid database TLS configuration")
+		}
+
+		opts = append(opts, pgdriver.WithTLSConfig(tlsCfg))
+	}
+
+	sqlDB := sql.OpenDB(pgdriver.NewConnector(
+		opts...,
+	))
+
+	pool := cfg.DB.Pool
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
+	return bun.NewDB(sqlDB, pgdialect.New()), nil
+}
+
+// withSessionTimeouts sets the statement and lock timeout on every new connection.
+// The parameters of the URI are kept.
+func withSessionTimeouts(statement, lock time.Duration) pgdriver.Option {
+	return func(cfg *pgdriver.Config) {
+		if cfg.ConnParams == nil {
+			cfg.ConnParams = make(map[string]any, 2)
+		}
+
+		cfg.ConnParams["statement_timeout"] = statement.Milliseconds()
+		cfg.ConnParams["lock_timeout"] = lock.Milliseconds()
+	}
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+)
+
+// DropTables drops all tables of the database, including the migration tables.
+// For Postgres only the tables of the current schema are dropped.
+//
+// NOTE: All data is lost, this must only be used for development databases.
+func DropTables(ctx context.Context) error {
+	var (
+		tables []string
+		err    error
+	)
+
+	switch dialect {
+	case DialectSQLite:
+		err = db.NewSelect().
+			Table("sqlite_master").
+			Column("name").
+			Where("type = 'table'").
+			Where("name NOT LIKE 'sqlite_%'").
+			Scan(ctx, &tables)
+	default:
+		err = db.NewSelect().
+			Table("pg_tables").
+			Column("tablename").
+			Where("schemaname = current_schema()").
+			Scan(ctx, &tables)
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to list tables")
+	}
+
+	if len(tables) == 0 {
+		return nil
+	}
+
+	if dialect == DialectSQLite {
+		// the tables are dropped in any order, SQLite would check the
+		// foreign keys of every dropped table otherwise.
+		// This works because only a single connection is used.
+		if _, err := db.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return errors.Wrap(err, "unable to disable foreign keys")
+		}
+		defer db.ExecContext(ctx, "PRAGMA foreign_keys = ON") //nolint:errcheck
+
+		for _, t := range tables {
+			if _, err := db.NewDropTable().Table(t).IfExists().Exec(ctx); err != nil {
+				return errors.Wrapf(err, "unable to drop table %s", t)
+			}
+		}
+
+		return nil
+	}
+
+	_, err = db.ExecContext(ctx, "DROP TABLE IF EXISTS ? CASCADE", bun.In(identifiers(tables)))
+
+	return errors.Wrap(err, "unable to drop tables")
+}
+
+// identifiers converts the names to SQL identifiers.
+func identifiers(names []string) []bun.Ident {
+	idents := make([]bun.Ident, len(names))
+	for i, n := range names {
+		idents[i] = bun.Ident(n)
+	}
+
+	return idents
+}
@@ -20,7 +20,7 @@ type Session struct {
 
 // Create inserts the object into the table.
 func (s *Session) Create(ctx context.Context) error {
-	_, err := db.NewInsert().
+	_, err := IDB(ctx).NewInsert().
 		Model(s).
 		Returning("*").
 		Exec(ctx)
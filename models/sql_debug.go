@@ -0,0 +1,54 @@
+package models
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/extra/bundebug"
+)
+
+// sqlDebug enables the SQL debug output at runtime.
+var sqlDebug atomic.Bool
+
+// sqlDebugHook is a [bun.QueryHook] which prints all queries, if the SQL debug output is enabled.
+type sqlDebugHook struct {
+	hook *bundebug.QueryHook
+}
+
+var _ bun.QueryHook = (*sqlDebugHook)(nil)
+
+func newSQLDebugHook() *sqlDebugHook {
+	return &sqlDebugHook{
+		hook: bundebug.NewQueryHook(bundebug.WithVerbose(true)),
+	}
+}
+
+// BeforeQuery implements [bun.QueryHook].
+func (h *sqlDebugHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	if !sqlDebug.Load() {
+		return ctx
+	}
+
+	return h.hook.BeforeQuery(ctx, event)
+}
+
+// AfterQuery implements [bun.QueryHook].
+func (h *sqlDebugHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	if !sqlDebug.Load() {
+		return
+	}
+
+	h.hook.AfterQuery(ctx, event)
+}
+
+// SetSQLDebug enables or disables the SQL debug output.
+// The previous state is returned.
+func SetSQLDebug(enabled bool) bool {
+	return sqlDebug.Swap(enabled)
+}
+
+// SQLDebug returns true if the SQL debug output is enabled.
+func SQLDebug() bool {
+	return sqlDebug.Load()
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+// sqlitePragmas are set on every SQLite connection.
+// Foreign keys are disabled by default in SQLite.
+//
+// They are executed on connect instead of being passed in the DSN,
+// since the DSN parameters differ between the drivers of sqliteshim.
+var sqlitePragmas = []string{
+	"PRAGMA foreign_keys = ON",
+	"PRAGMA busy_timeout = 5000",
+}
+
+// openSQLite opens the SQLite database.
+//
+// The URI has the format "sqlite:<path>", i.e. "sqlite:/var/lib/briefkasten/briefkasten.db"
+// or "sqlite::memory:" for an in-memory database.
+//
+// A single connection is used, because SQLite does not support concurrent
+// writes and every connection to an in-memory database has its own database.
+// The pool, TLS and timeout settings are ignored.
+func openSQLite(cfg *config.Config) (*bun.DB, error) {
+	dsn := strings.TrimPrefix(strings.TrimPrefix(cfg.DB.URI, "sqlite://"), "sqlite:")
+	if dsn == "" {
+		return nil, errors.New("database path missing in SQLite URI")
+	}
+
+	sqlDB := sql.OpenDB(sqliteConnector{dsn: dsn})
+
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	sqlDB.SetConnMaxIdleTime(0)
+
+	return bun.NewDB(sqlDB, sqlitedialect.New()), nil
+}
+
+// sqliteConnector opens SQLite connections and sets the [sqlitePragmas] on them.
+type sqliteConnector struct {
+	dsn string
+}
+
+// Connect implements [driver.Connector].
+func (c sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open SQLite database")
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		conn.Close()
+		return nil, errors.New("SQLite driver does not support executing statements")
+	}
+
+	for _, p := range sqlitePragmas {
+		if _, err := execer.ExecContext(ctx, p, nil); err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "unable to execute %q", p)
+		}
+	}
+
+	return conn, nil
+}
+
+// Driver implements [driver.Connector].
+func (c sqliteConnector) Driver() driver.Driver {
+	return sqliteshim.Driver()
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+func TestSQLitePragmas(t *testing.T) {
+	var cfg config.Config
+	cfg.DB.URI = "sqlite:" + filepath.Join(t.TempDir(), "briefkasten.db")
+
+	db, err := openSQLite(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	// the pragmas must also be set on connections opened later on
+	for i := 0; i < 2; i++ {
+		var foreignKeys, busyTimeout int
+
+		if err := db.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := db.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&busyTimeout); err != nil {
+			t.Fatal(err)
+		}
+
+		if foreignKeys != 1 || busyTimeout != 5000 {
+			t.Errorf("connection %d: expected foreign_keys=1 and busy_timeout=5000, got %d and %d", i, foreignKeys, busyTimeout)
+		}
+
+		// closes the idle connection
+		db.SetMaxIdleConns(0)
+		db.SetMaxIdleConns(1)
+	}
+
+	// ON DELETE CASCADE works
+	for _, q := range []string{
+		"CREATE TABLE parent (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE child (id INTEGER PRIMARY KEY, parent_id INTEGER REFERENCES parent (id) ON DELETE CASCADE)",
+		"INSERT INTO parent (id) VALUES (1)",
+		"INSERT INTO child (id, parent_id) VALUES (1, 1)",
+		"DELETE FROM parent",
+	} {
+		if _, err := db.ExecContext(ctx, q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+
+	var children int
+	if err := db.QueryRowContext(ctx, "SELECT count(*) FROM child").Scan(&children); err != nil {
+		t.Fatal(err)
+	}
+
+	if children != 0 {
+		t.Errorf("expected the child to be deleted by the cascade, got %d children", children)
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Stats holds the number of entries of the main tables.
+type Stats struct {
+	Users     int
+	Bookmarks int
+	Tags      int
+}
+
+// GetStats counts the entries of the main tables.
+func GetStats(ctx context.Context) (Stats, error) {
+	var (
+		s   Stats
+		err error
+	)
+
+	idb := IDB(ctx)
+
+	if s.Users, err = idb.NewSelect().Model((*UserAccount)(nil)).Count(ctx); err != nil {
+		return s, errors.Wrap(err, "unable to count users")
+	}
+
+	if s.Bookmarks, err = idb.NewSelect().Model((*Bookmark)(nil)).Count(ctx); err != nil {
+		return s, errors.Wrap(err, "unable to count bookmarks")
+	}
+
+	if s.Tags, err = idb.NewSelect().Model((*Tag)(nil)).Count(ctx); err != nil {
+		return s, errors.Wrap(err, "unable to count tags")
+	}
+
+	return s, nil
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+// newTLSConfig returns the TLS configuration of the given SSL mode, which
+// follows the semantics of the libpq sslmode:
+//
+//   - disable: no TLS
+//   - require: TLS without verifying the server certificate
+//   - verify-ca: TLS, the server certificate must be signed by a trusted CA
+//   - verify-full: like verify-ca, additionally the host name must match the certificate
+//
+// The host name is taken from the connection URI.
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	t := cfg.DB.TLS
+
+	if t.Mode == "disable" {
+		return nil, nil
+	}
+
+	// the certificates are loaded like the ones of all other connections,
+	// only the verification depends on the SSL mode
+	tlsCfg, err := config.TLS{
+		Enabled:  true,
+		CAFile:   t.CAFile,
+		CertFile: t.CertFile,
+		KeyFile:  t.KeyFile,
+	}.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.Mode {
+	case "require":
+		tlsCfg.InsecureSkipVerify = true //nolint:gosec // required by the sslmode semantics
+	case "verify-ca":
+		// the certificate chain is verified manually, because the default
+		// verification would also verify the host name
+		tlsCfg.InsecureSkipVerify = true //nolint:gosec // the chain is verified below
+		tlsCfg.VerifyPeerCertificate = verifyChain(tlsCfg.RootCAs)
+	case "verify-full":
+		u, err := url.Parse(cfg.DB.URI)
+		if err != nil {
+			return nil, errors.New("unable to parse database URI")
+		}
+
+		tlsCfg.ServerName = u.Hostname()
+	default:
+		return nil, errors.Errorf("unsupported SSL mode %q", t.Mode)
+	}
+
+	return tlsCfg, nil
+}
+
+// verifyChain returns a function verifying that the certificate
+// chain of the server is signed by one of the given CAs.
+// If roots is nil, the system CA pool is used.
+func verifyChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("server did not present a certificate")
+		}
+
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return errors.Wrap(err, "unable to parse server certificate")
+			}
+
+			certs[i] = cert
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+
+		return errors.Wrap(err, "unable to verify server certificate")
+	}
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fabmation-gmbh/briefkasten-go/internal/config"
+)
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	ca, caKey := newTestCA(t, "briefkasten test CA")
+	other, _ := newTestCA(t, "other CA")
+
+	caFile := writePEM(t, filepath.Join(dir, "ca.pem"), ca.Raw)
+	otherFile := writePEM(t, filepath.Join(dir, "other.pem"), other.Raw)
+
+	// the certificate does not match the host name of the URI
+	serverCert := newTestServerCert(t, ca, caKey, "db.internal")
+
+	tests := []struct {
+		mode   string
+		caFile string
+		err    bool
+	}{
+		{mode: "require", caFile: otherFile},
+		{mode: "verify-ca", caFile: caFile},
+		{mode: "verify-ca", caFile: otherFile, err: true},
+		{mode: "verify-full", caFile: caFile, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+" "+filepath.Base(tt.caFile), func(t *testing.T) {
+			var cfg config.Config
+			cfg.DB.URI = "postgres://briefkasten@localhost/briefkasten"
+			cfg.DB.TLS.Mode = tt.mode
+			cfg.DB.TLS.CAFile = tt.caFile
+
+			tlsCfg, err := newTLSConfig(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = handshake(t, tlsCfg, serverCert)
+			if tt.err && err == nil {
+				t.Error("expected the handshake to fail")
+			} else if !tt.err && err != nil {
+				t.Errorf("unexpected handshake error: %v", err)
+			}
+		})
+	}
+
+	t.Run("verify-ca without certificate", func(t *testing.T) {
+		var cfg config.Config
+		cfg.DB.TLS.Mode = "verify-ca"
+		cfg.DB.TLS.CAFile = caFile
+
+		tlsCfg, err := newTLSConfig(&cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := tlsCfg.VerifyPeerCertificate(nil, nil); err == nil {
+			t.Error("expected an error if the server presents no certificate")
+		}
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		var cfg config.Config
+		cfg.DB.TLS.Mode = "disable"
+
+		if tlsCfg, err := newTLSConfig(&cfg); err != nil || tlsCfg != nil {
+			t.Errorf("expected no TLS configuration, got %v (%v)", tlsCfg, err)
+		}
+	})
+}
+
+// handshake performs a TLS handshake with a server using the certificate.
+func handshake(t *testing.T, clientCfg *tls.Config, serverCert tls.Certificate) error {
+	t.Helper()
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+
+	go func() {
+		defer serverConn.Close()
+
+		server := tls.Server(serverConn, &tls.Config{Certificates: []tls.Certificate{serverCert}})
+		_ = server.Handshake()
+	}()
+
+	_ = clientConn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	return tls.Client(clientConn, clientCfg).Handshake()
+}
+
+func newTestCA(t *testing.T, name string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, key
+}
+
+func newTestServerCert(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, host string) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func writePEM(t *testing.T, path string, der []byte) string {
+	t.Helper()
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
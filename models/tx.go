@@ -0,0 +1,55 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/uptrace/bun"
+)
+
+// txKey is the context key of the current transaction.
+type txKey struct{}
+
+// ContextWithTx returns a copy of ctx carrying the transaction.
+// All database calls using the returned context are part of the transaction.
+func ContextWithTx(ctx context.Context, tx bun.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns the transaction carried by the context, if any.
+func TxFromContext(ctx context.Context) (bun.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(bun.Tx)
+
+	return tx, ok
+}
+
+// RunInTx runs fn in a transaction of the given database, which is carried
+// by the context passed to fn. The transaction is committed if fn returns
+// nil and rolled back otherwise.
+//
+// If ctx already carries a transaction, fn is part of that transaction.
+func RunInTx(ctx context.Context, db *bun.DB, fn func(ctx context.Context) error) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	return db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		return fn(ContextWithTx(ctx, tx))
+	})
+}
+
+// idb returns the transaction of the context, or the given
+// database if there is none.
+func idb(ctx context.Context, db *bun.DB) bun.IDB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+
+	return db
+}
+
+// IDB returns the transaction of the context, or the database
+// connection if there is none.
+func IDB(ctx context.Context) bun.IDB {
+	return idb(ctx, db)
+}
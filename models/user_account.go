@@ -1,11 +1,9 @@
 package models
 
 import (
-	"context"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/pkg/errors"
 	"github.com/uptrace/bun"
 	"gopkg.in/guregu/null.v4"
 )
@@ -19,32 +17,32 @@ type UserAccount struct {
 	Email         string      `bun:"email" json:"email"`
 	EmailVerified bool        `bun:"email_verified" json:"email_verified"`
 	Image         null.String `bun:"image" json:"image"`
+	Role          string      `bun:"role" json:"role"`
+	// DisabledAt is the time the account has been disabled, null if it is enabled.
+	DisabledAt null.Time `bun:"disabled_at" json:"disabled_at"`
+	// PreferredLanguage is the BCP 47 language tag preferred by the user, i.e. "de-DE".
+	PreferredLanguage null.String `bun:"preferred_language" json:"preferred_language"`
+	// Timezone is the IANA time zone of the user, i.e. "Europe/Berlin".
+	Timezone null.String `bun:"timezone" json:"timezone"`
+	// DefaultCategoryID is the category preselected for new bookmarks.
+	DefaultCategoryID uuid.NullUUID `bun:"default_category_id" json:"default_category_id"`
+	// TokenGeneration is the generation of the issued tokens. Tokens of an
+	// older generation are rejected, it is incremented to revoke all tokens.
+	TokenGeneration int64 `bun:"token_generation" json:"-"`
 }
 
-// Create inserts the object into the table.
-func (u *UserAccount) Create(ctx context.Context) error {
-	_, err := db.NewInsert().
-		Model(u).
-		Returning("*").
-		Exec(ctx)
+// Roles of the users.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
 
-	return errors.Wrap(err, "unable to insert user into DB")
+// IsAdmin returns true if the user has the admin role.
+func (u UserAccount) IsAdmin() bool {
+	return u.Role == RoleAdmin
 }
 
-// GetOrCreateUser returns the user object from the database.
-// The user will be searched by its username.
-// If the user does not exist, it will be created.
-func GetOrCreateUser(ctx context.Context, u UserAccount) (UserAccount, error) {
-	var ret UserAccount
-
-	err := db.NewSelect().
-		Model(&ret).
-		Where("email = ?", u.Email).
-		Limit(1).
-		Scan(ctx)
-	if err != nil && IsNoRows(err) {
-		return u, u.Create(ctx)
-	}
-
-	return ret, errors.Wrap(err, "unable to find user in DB")
+// IsDisabled returns true if the account has been disabled.
+func (u UserAccount) IsDisabled() bool {
+	return u.DisabledAt.Valid
 }
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"github.com/fabmation-gmbh/briefkasten-go/handler"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/audit"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/auth"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/redis"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store"
+	"github.com/fabmation-gmbh/briefkasten-go/internal/store/bunstore"
+	"github.com/fabmation-gmbh/briefkasten-go/models"
+)
+
+// users is the user store of the user commands.
+var users store.UserStore
+
+func newUserCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "user",
+		Usage: "user account management",
+		Before: func(c *cli.Context) error {
+			if err := connectDB(c); err != nil {
+				return err
+			}
+
+			users = bunstore.New(models.GetDB()).Users
+
+			return nil
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "list all users",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "query",
+						Usage: "only list users whose name or email address contains the query",
+					},
+					&cli.IntFlag{
+						Name:  "limit",
+						Usage: "maximum number of listed users",
+						Value: 100,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "ID\tEMAIL\tNAME\tROLE\tCREATED\tDISABLED")
+
+					var page store.Page
+
+					for n := 0; n < c.Int("limit"); {
+						page.Limit = c.Int("limit") - n
+
+						list, err := users.List(c.Context, c.String("query"), page)
+						if err != nil {
+							return err
+						}
+
+						for _, u := range list {
+							fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+								u.ID, u.Email, u.Name, u.Role, u.CreatedAt.Format(time.RFC3339), formatDisabled(u))
+						}
+
+						n += len(list)
+						if len(list) < page.Size() {
+							break
+						}
+
+						last := list[len(list)-1]
+						page.AfterID, page.AfterTime = last.ID, last.CreatedAt
+					}
+
+					return w.Flush()
+				},
+			},
+			{
+				Name:      "show",
+				Usage:     "print a user as JSON",
+				ArgsUsage: "<id|email>",
+				Action: func(c *cli.Context) error {
+					u, err := lookupUser(c)
+					if err != nil {
+						return err
+					}
+
+					enc := json.NewEncoder(os.Stdout)
+					enc.SetIndent("", "  ")
+
+					return enc.Encode(u)
+				},
+			},
+			{
+				Name:  "create",
+				Usage: "create a user",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "email",
+						Usage:    "email address of the user",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "name of the user",
+					},
+					&cli.BoolFlag{
+						Name:  "admin",
+						Usage: "grant the admin role",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					u := models.UserAccount{
+						Email: c.String("email"),
+						Name:  c.String("name"),
+						Role:  models.RoleUser,
+					}
+
+					if c.Bool("admin") {
+						u.Role = models.RoleAdmin
+					}
+
+					if err := users.Create(c.Context, &u); err != nil {
+						return err
+					}
+
+					recordUserAction(c, "user.create", nil, u)
+					fmt.Printf("created user %s (%s)\n", u.ID, u.Email)
+
+					return nil
+				},
+			},
+			{
+				Name:      "disable",
+				Usage:     "disable a user and revoke all tokens and sessions, the data of the user is kept",
+				ArgsUsage: "<id|email>",
+				Before:    connectRedis,
+				Action: func(c *cli.Context) error {
+					return setDisabled(c, true)
+				},
+			},
+			{
+				Name:      "enable",
+				Usage:     "enable a disabled user",
+				ArgsUsage: "<id|email>",
+				Action: func(c *cli.Context) error {
+					return setDisabled(c, false)
+				},
+			},
+			{
+				Name:      "delete",
+				Usage:     "delete a user including all categories, tags, bookmarks and sessions",
+				ArgsUsage: "<id|email>",
+				Before:    connectRedis,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "do not ask for confirmation",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					u, err := lookupUser(c)
+					if err != nil {
+						return err
+					}
+
+					if !c.Bool("yes") && !confirm(fmt.Sprintf("Delete user %s (%s) and all of their data?", u.ID, u.Email)) {
+						return cli.Exit("aborted", 1)
+					}
+
+					if err := users.Delete(c.Context, u.ID); err != nil {
+						return err
+					}
+
+					recordUserAction(c, "user.delete", u, nil)
+
+					if err := redis.PurgeUser(c.Context, u.ID); err != nil {
+						return errors.Wrapf(err, "deleted user %s, but unable to delete the sessions", u.ID)
+					}
+
+					fmt.Printf("deleted user %s (%s)\n", u.ID, u.Email)
+
+					return nil
+				},
+			},
+			{
+				Name:      "set-admin",
+				Usage:     "grant or revoke the admin role",
+				ArgsUsage: "<id|email>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "revoke",
+						Usage: "revoke the admin role",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					u, err := lookupUser(c)
+					if err != nil {
+						return err
+					}
+
+					role := models.RoleAdmin
+					if c.Bool("revoke") {
+						role = models.RoleUser
+					}
+
+					if err := users.SetRole(c.Context, u.ID, role); err != nil {
+						return err
+					}
+
+					recordUserAction(c, "user.role", u.Role, role)
+					fmt.Printf("set role of user %s (%s) to %s\n", u.ID, u.Email, role)
+
+					return nil
+				},
+			},
+			{
+				Name:      "issue-token",
+				Usage:     "issue a JWT of a user for support and debugging",
+				ArgsUsage: "<id|email>",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:  "ttl",
+						Usage: "lifetime of the token",
+						Value: time.Hour,
+					},
+					&cli.StringFlag{
+						Name:     "reason",
+						Usage:    "reason for the token, stored in the audit trail",
+						Required: true,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					u, err := lookupUser(c)
+					if err != nil {
+						return err
+					}
+
+					if u.IsDisabled() {
+						return errors.Errorf("user %s is disabled", u.ID)
+					}
+
+					ttl := c.Duration("ttl")
+					if ttl <= 0 || ttl > auth.SessionTTL {
+						return errors.Errorf("ttl must be between 0 and %s", auth.SessionTTL)
+					}
+
+					// a token must never be issued without an entry in the audit trail
+					err = audit.Store(c.Context, audit.Entry{
+						Actor:  cliActor(),
+						Action: "user.issue_token",
+						NewValue: map[string]any{
+							"user_id":    u.ID,
+							"expires_at": time.Now().Add(ttl).UTC(),
+							"reason":     c.String("reason"),
+						},
+					})
+					if err != nil {
+						return errors.Wrap(err, "unable to record the token in the audit trail")
+					}
+
+					token, err := auth.NewToken(u, ttl)
+					if err != nil {
+						return err
+					}
+
+					fmt.Println(token)
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// lookupUser returns the user identified by the first argument,
+// which is either the ID or the email address.
+func lookupUser(c *cli.Context) (models.UserAccount, error) {
+	arg := c.Args().First()
+	if arg == "" {
+		return models.UserAccount{}, errors.New("missing user ID or email address")
+	}
+
+	if id, err := uuid.Parse(arg); err == nil {
+		return users.Get(c.Context, id)
+	}
+
+	return users.GetByEmail(c.Context, arg)
+}
+
+// setDisabled disables or enables the user of the first argument.
+func setDisabled(c *cli.Context, disabled bool) error {
+	u, err := lookupUser(c)
+	if err != nil {
+		return err
+	}
+
+	if err := users.SetDisabled(c.Context, u.ID, disabled); err != nil {
+		return err
+	}
+
+	if disabled {
+		if err := users.RevokeTokens(c.Context, u.ID); err != nil {
+			return err
+		}
+
+		if err := redis.PurgeUser(c.Context, u.ID); err != nil {
+			return errors.Wrapf(err, "disabled user %s, but unable to delete the sessions", u.ID)
+		}
+	}
+
+	action := "user.enable"
+	if disabled {
+		action = "user.disable"
+	}
+
+	recordUserAction(c, action,
+		map[string]any{"user_id": u.ID, "disabled": u.IsDisabled()},
+		map[string]any{"user_id": u.ID, "disabled": disabled},
+	)
+	fmt.Printf("%sd user %s (%s)\n", strings.TrimPrefix(action, "user."), u.ID, u.Email)
+
+	return nil
+}
+
+// connectRedis connects to redis, which stores the sessions of the users.
+func connectRedis(*cli.Context) error {
+	return errors.Wrap(handler.Connect(), "unable to connect to redis")
+}
+
+// recordUserAction writes the action to the audit trail.
+func recordUserAction(c *cli.Context, action string, oldValue, newValue any) {
+	audit.Record(c.Context, audit.Entry{
+		Actor:    cliActor(),
+		Action:   action,
+		OldValue: oldValue,
+		NewValue: newValue,
+	})
+}
+
+// cliActor returns the audit trail actor of the CLI, including the OS user.
+func cliActor() string {
+	actor := "cli"
+	if u, err := user.Current(); err == nil {
+		actor += ":" + u.Username
+	}
+
+	return actor
+}
+
+// confirm asks the question on the terminal and returns true if it is confirmed.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// formatDisabled returns the time the user has been disabled, or "-".
+func formatDisabled(u models.UserAccount) string {
+	if !u.IsDisabled() {
+		return "-"
+	}
+
+	return u.DisabledAt.Time.Format(time.RFC3339)
+}